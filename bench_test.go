@@ -0,0 +1,82 @@
+package jrpc_test
+
+import (
+	"context"
+	"io"
+
+	"testing"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+func newBenchRouter() *jrpc.Router {
+	router := jrpc.NewRouter()
+
+	router.Method(subtractHandler.method, subtractHandler.handlerFunc)
+	router.Method(getDataHandler.method, getDataHandler.handlerFunc)
+	router.Method("error", func(ctx context.Context) (any, error) {
+		return nil, jrpc.InternalError("something \"went\" wrong\n")
+	})
+
+	return router
+}
+
+func BenchmarkHandle_Single(b *testing.B) {
+	router := newBenchRouter()
+	request := []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		router.Handle(context.Background(), request)
+	}
+}
+
+func BenchmarkHandle_Error(b *testing.B) {
+	router := newBenchRouter()
+	request := []byte(`{"jsonrpc": "2.0", "method": "error", "id": "1"}`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		router.Handle(context.Background(), request)
+	}
+}
+
+func BenchmarkHandle_Batch(b *testing.B) {
+	router := newBenchRouter()
+	request := []byte(`[
+		{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1},
+		{"jsonrpc": "2.0", "method": "get_data", "id": 2},
+		{"jsonrpc": "2.0", "method": "error", "id": 3},
+		{"jsonrpc": "2.0", "method": "foobar", "id": 4}
+	]`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		router.Handle(context.Background(), request)
+	}
+}
+
+func BenchmarkHandleTo_Batch(b *testing.B) {
+	router := newBenchRouter()
+	request := []byte(`[
+		{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1},
+		{"jsonrpc": "2.0", "method": "get_data", "id": 2},
+		{"jsonrpc": "2.0", "method": "error", "id": 3},
+		{"jsonrpc": "2.0", "method": "foobar", "id": 4}
+	]`)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := router.HandleTo(context.Background(), io.Discard, request); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
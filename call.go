@@ -0,0 +1,54 @@
+package jrpc
+
+import "context"
+
+type callKey struct{}
+
+type callInfo struct {
+	method         string
+	batchIndex     int
+	isBatch        bool
+	isNotification bool
+
+	codec Codec
+}
+
+func setCallInfo(ctx context.Context, info callInfo) context.Context {
+	return context.WithValue(ctx, callKey{}, info)
+}
+
+func getCallInfo(ctx context.Context) (callInfo, bool) {
+	info, ok := ctx.Value(callKey{}).(callInfo)
+
+	return info, ok
+}
+
+// Method returns the name of the called method.
+func Method(ctx context.Context) string {
+	info, _ := getCallInfo(ctx)
+
+	return info.method
+}
+
+// BatchIndex returns the index of the call in the batch, or -1 if the call is not a batch member.
+func BatchIndex(ctx context.Context) int {
+	info, ok := getCallInfo(ctx)
+	if !ok || !info.isBatch {
+		return -1
+	}
+
+	return info.batchIndex
+}
+
+func IsBatch(ctx context.Context) bool {
+	info, _ := getCallInfo(ctx)
+
+	return info.isBatch
+}
+
+// IsNotification reports whether the call is a notification, so the client doesn't expect a response.
+func IsNotification(ctx context.Context) bool {
+	info, _ := getCallInfo(ctx)
+
+	return info.isNotification
+}
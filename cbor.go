@@ -0,0 +1,404 @@
+package jrpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/valyala/fastjson"
+)
+
+const (
+	cborUint   = 0 << 5
+	cborNegInt = 1 << 5
+	cborBytes  = 2 << 5
+	cborText   = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborTag    = 6 << 5
+	cborSimple = 7 << 5
+
+	cborIndefinite = 31
+	cborBreak      = 0xff
+)
+
+type cborEncoding struct{}
+
+func (cborEncoding) ContentType() string {
+	return "application/cbor"
+}
+
+func (cborEncoding) FromJSON(msg []byte) ([]byte, error) {
+	v, err := parseJSONForWire(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendCBOR(nil, v)
+}
+
+func (cborEncoding) ToJSON(msg []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	d := &cborDecoder{data: msg}
+	if err := d.decode(buf, 0); err != nil {
+		return nil, fmt.Errorf("cbor: %w", err)
+	}
+
+	if d.pos != len(d.data) {
+		return nil, errors.New("cbor: unexpected data after the value")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func appendCBOR(dst []byte, v *fastjson.Value) ([]byte, error) {
+	switch v.Type() {
+	case fastjson.TypeNull:
+		return append(dst, cborSimple|22), nil
+
+	case fastjson.TypeTrue:
+		return append(dst, cborSimple|21), nil
+
+	case fastjson.TypeFalse:
+		return append(dst, cborSimple|20), nil
+
+	case fastjson.TypeNumber:
+		u, i, f, kind, err := jsonNumber(v.String())
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case 'u':
+			return appendCBORHeader(dst, cborUint, u), nil
+		case 'i':
+			return appendCBORHeader(dst, cborNegInt, uint64(-1-i)), nil
+		default:
+			dst = append(dst, cborSimple|27)
+
+			return binary.BigEndian.AppendUint64(dst, math.Float64bits(f)), nil
+		}
+
+	case fastjson.TypeString:
+		s := v.GetStringBytes()
+
+		return append(appendCBORHeader(dst, cborText, uint64(len(s))), s...), nil
+
+	case fastjson.TypeArray:
+		arr := v.GetArray()
+
+		dst = appendCBORHeader(dst, cborArray, uint64(len(arr)))
+
+		for _, item := range arr {
+			var err error
+			if dst, err = appendCBOR(dst, item); err != nil {
+				return nil, err
+			}
+		}
+
+		return dst, nil
+
+	case fastjson.TypeObject:
+		obj := v.GetObject()
+
+		dst = appendCBORHeader(dst, cborMap, uint64(obj.Len()))
+
+		var err error
+
+		obj.Visit(func(key []byte, item *fastjson.Value) {
+			if err != nil {
+				return
+			}
+
+			dst = append(appendCBORHeader(dst, cborText, uint64(len(key))), key...)
+			dst, err = appendCBOR(dst, item)
+		})
+
+		return dst, err
+
+	default:
+		return nil, fmt.Errorf("cbor: unsupported JSON value type %s", v.Type())
+	}
+}
+
+func appendCBORHeader(dst []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(dst, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(dst, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, major|27), n)
+	}
+}
+
+type cborDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *cborDecoder) next(n uint64) ([]byte, error) {
+	if uint64(len(d.data)-d.pos) < n {
+		return nil, errors.New("unexpected end of data")
+	}
+
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+
+	return b, nil
+}
+
+// header reads the initial byte and the argument of the data item.
+func (d *cborDecoder) header() (major byte, info byte, arg uint64, err error) {
+	b, err := d.next(1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	major, info = b[0]&0xe0, b[0]&0x1f
+
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info <= 27:
+		ext, err := d.next(1 << (info - 24))
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		switch len(ext) {
+		case 1:
+			arg = uint64(ext[0])
+		case 2:
+			arg = uint64(binary.BigEndian.Uint16(ext))
+		case 4:
+			arg = uint64(binary.BigEndian.Uint32(ext))
+		default:
+			arg = binary.BigEndian.Uint64(ext)
+		}
+
+		return major, info, arg, nil
+	case info == cborIndefinite:
+		return major, info, 0, nil
+	default:
+		return 0, 0, 0, fmt.Errorf("invalid additional info %d", info)
+	}
+}
+
+func (d *cborDecoder) isBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == cborBreak {
+		d.pos++
+
+		return true
+	}
+
+	return false
+}
+
+func (d *cborDecoder) decode(buf *bytes.Buffer, depth int) error {
+	if depth > maxWireDepth {
+		return errWireDepth
+	}
+
+	major, info, arg, err := d.header()
+	if err != nil {
+		return err
+	}
+
+	if info == cborIndefinite && (major == cborUint || major == cborNegInt || major == cborTag) {
+		return errors.New("indefinite length is not allowed for the major type")
+	}
+
+	switch major {
+	case cborUint:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), arg, 10))
+
+	case cborNegInt:
+		if arg == math.MaxUint64 {
+			buf.WriteString("-18446744073709551616")
+
+			return nil
+		}
+
+		buf.WriteByte('-')
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), arg+1, 10))
+
+	case cborBytes, cborText:
+		s, err := d.decodeChunks(major, info, arg)
+		if err != nil {
+			return err
+		}
+
+		if major == cborBytes {
+			buf.WriteByte('"')
+			buf.WriteString(base64.StdEncoding.EncodeToString(s))
+			buf.WriteByte('"')
+
+			return nil
+		}
+
+		writeString(buf, string(s))
+
+	case cborArray:
+		buf.WriteByte('[')
+
+		for i := uint64(0); info == cborIndefinite || i < arg; i++ {
+			if info == cborIndefinite && d.isBreak() {
+				break
+			}
+
+			if info != cborIndefinite && arg-i > uint64(len(d.data)-d.pos) {
+				return errors.New("length exceeds data size")
+			}
+
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+
+			if err = d.decode(buf, depth+1); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte(']')
+
+	case cborMap:
+		buf.WriteByte('{')
+
+		for i := uint64(0); info == cborIndefinite || i < arg; i++ {
+			if info == cborIndefinite && d.isBreak() {
+				break
+			}
+
+			if info != cborIndefinite && arg-i > uint64(len(d.data)-d.pos) {
+				return errors.New("length exceeds data size")
+			}
+
+			if i != 0 {
+				buf.WriteByte(',')
+			}
+
+			if err = d.decodeKey(buf, depth); err != nil {
+				return err
+			}
+
+			buf.WriteByte(':')
+
+			if err = d.decode(buf, depth+1); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteByte('}')
+
+	case cborTag:
+		// tags are semantic hints, the tagged value is translated as is
+		return d.decode(buf, depth+1)
+
+	default:
+		return d.decodeSimple(buf, info, arg)
+	}
+
+	return nil
+}
+
+func (d *cborDecoder) decodeChunks(major, info byte, arg uint64) ([]byte, error) {
+	if info != cborIndefinite {
+		return d.next(arg)
+	}
+
+	var s []byte
+
+	for !d.isBreak() {
+		chunkMajor, chunkInfo, chunkArg, err := d.header()
+		if err != nil {
+			return nil, err
+		}
+
+		if chunkMajor != major || chunkInfo == cborIndefinite {
+			return nil, errors.New("invalid chunk of indefinite length string")
+		}
+
+		chunk, err := d.next(chunkArg)
+		if err != nil {
+			return nil, err
+		}
+
+		s = append(s, chunk...)
+	}
+
+	return s, nil
+}
+
+func (d *cborDecoder) decodeSimple(buf *bytes.Buffer, info byte, arg uint64) error {
+	switch info {
+	case 20:
+		buf.WriteString("false")
+	case 21:
+		buf.WriteString("true")
+	case 22, 23:
+		buf.WriteString("null")
+	case 25:
+		return writeJSONFloat(buf, float16ToFloat64(uint16(arg)))
+	case 26:
+		return writeJSONFloat(buf, float64(math.Float32frombits(uint32(arg))))
+	case 27:
+		return writeJSONFloat(buf, math.Float64frombits(arg))
+	default:
+		return fmt.Errorf("unsupported simple value %d", arg)
+	}
+
+	return nil
+}
+
+// decodeKey writes map key as JSON string, numeric keys are converted into strings.
+func (d *cborDecoder) decodeKey(buf *bytes.Buffer, depth int) error {
+	if d.pos < len(d.data) && d.data[d.pos]&0xe0 == cborText {
+		return d.decode(buf, depth+1)
+	}
+
+	key := &bytes.Buffer{}
+	if err := d.decode(key, depth+1); err != nil {
+		return err
+	}
+
+	if _, err := strconv.ParseFloat(key.String(), 64); err != nil {
+		return errors.New("map key must be a string or a number")
+	}
+
+	writeString(buf, key.String())
+
+	return nil
+}
+
+func float16ToFloat64(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1.0
+	}
+
+	exp := int(h>>10) & 0x1f
+	frac := float64(h & 0x3ff)
+
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(frac, -24)
+	case 0x1f:
+		if frac == 0 {
+			return math.Inf(int(sign))
+		}
+
+		return math.NaN()
+	default:
+		return sign * math.Ldexp(frac+1024, exp-25)
+	}
+}
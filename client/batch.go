@@ -0,0 +1,100 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/goccy/go-json"
+)
+
+type Batch struct {
+	client *Client
+
+	requests []request
+	calls    map[uint64]*BatchCall
+}
+
+type BatchCall struct {
+	Method string
+	Result any
+
+	err error
+}
+
+// Err returns the error of the call: *jrpc.Error if the server replied with error,
+// or ErrNoResponse if the response for the call is missing.
+func (call *BatchCall) Err() error {
+	return call.err
+}
+
+// Call adds a method call into the batch. Result will be decoded into result after Send.
+func (b *Batch) Call(method string, params any, result any) *BatchCall {
+	req := b.client.newRequest(method, params, false)
+
+	call := &BatchCall{Method: method, Result: result, err: ErrNoResponse}
+
+	if b.calls == nil {
+		b.calls = make(map[uint64]*BatchCall)
+	}
+
+	b.calls[*req.ID] = call
+	b.requests = append(b.requests, req)
+
+	return call
+}
+
+func (b *Batch) Notify(method string, params any) {
+	b.requests = append(b.requests, b.client.newRequest(method, params, true))
+}
+
+// Send sends the batch and matches responses with calls by id. Errors of the single calls are available by BatchCall.Err.
+func (b *Batch) Send(ctx context.Context) error {
+	if len(b.requests) == 0 {
+		return nil
+	}
+
+	body, err := b.client.send(ctx, b.requests)
+	if err != nil {
+		return err
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil
+	}
+
+	if body[0] != '[' {
+		var resp response
+		if err = json.Unmarshal(body, &resp); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+		}
+
+		if resp.Error != nil {
+			return resp.Error
+		}
+
+		return ErrInvalidResponse
+	}
+
+	var responses []response
+	if err = json.Unmarshal(body, &responses); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+
+	for i := range responses {
+		id, ok := responses[i].id()
+		if !ok {
+			continue
+		}
+
+		call, ok := b.calls[id]
+		if !ok {
+			continue
+		}
+
+		call.err = responses[i].decode(call.Result)
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/goccy/go-json"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+var (
+	ErrNoResponse      = errors.New("jrpc client: no response for request")
+	ErrInvalidResponse = errors.New("jrpc client: invalid response")
+)
+
+type Option func(*Client)
+
+type Client struct {
+	url        string
+	httpClient *http.Client
+	header     http.Header
+
+	nextID atomic.Uint64
+}
+
+func New(url string, opts ...Option) *Client {
+	c := &Client{
+		url:        url,
+		httpClient: http.DefaultClient,
+		header:     make(http.Header),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+type request struct {
+	JSONRPC string  `json:"jsonrpc"`
+	Method  string  `json:"method"`
+	Params  any     `json:"params,omitempty"`
+	ID      *uint64 `json:"id,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result"`
+	Error   *jrpc.Error     `json:"error"`
+	ID      json.RawMessage `json:"id"`
+}
+
+func (c *Client) newRequest(method string, params any, notification bool) request {
+	req := request{JSONRPC: "2.0", Method: method, Params: params}
+
+	if !notification {
+		id := c.nextID.Add(1)
+		req.ID = &id
+	}
+
+	return req
+}
+
+// Call calls the method and decodes the result into result. JSON-RPC errors are returned as *jrpc.Error,
+// including errors with null id, e.g. of unparsable request. Responses with other id are ErrInvalidResponse.
+func (c *Client) Call(ctx context.Context, method string, params any, result any) error {
+	req := c.newRequest(method, params, false)
+
+	body, err := c.send(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	var resp response
+	if err = json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+
+	if resp.nullID() {
+		if resp.Error != nil {
+			return resp.Error
+		}
+
+		return fmt.Errorf("%w: result with null id", ErrInvalidResponse)
+	}
+
+	if id, ok := resp.id(); !ok || id != *req.ID {
+		return fmt.Errorf("%w: got id %s, want %d", ErrInvalidResponse, resp.ID, *req.ID)
+	}
+
+	return resp.decode(result)
+}
+
+// Notify sends a notification, the server doesn't reply to it.
+func (c *Client) Notify(ctx context.Context, method string, params any) error {
+	_, err := c.send(ctx, c.newRequest(method, params, true))
+
+	return err
+}
+
+func (c *Client) NewBatch() *Batch {
+	return &Batch{client: c}
+}
+
+func (c *Client) send(ctx context.Context, payload any) ([]byte, error) {
+	reqBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("jrpc client: error during marshaling request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range c.header {
+		httpReq.Header[key] = values
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jrpc client: unexpected status code %d", httpResp.StatusCode)
+	}
+
+	return io.ReadAll(httpResp.Body)
+}
+
+func (resp *response) decode(result any) error {
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	if result == nil || len(resp.Result) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		return fmt.Errorf("jrpc client: error during unmarshaling result: %w", err)
+	}
+
+	return nil
+}
+
+func (resp *response) nullID() bool {
+	return len(resp.ID) == 0 || string(resp.ID) == "null"
+}
+
+func (resp *response) id() (uint64, bool) {
+	id, err := strconv.ParseUint(string(bytes.Trim(resp.ID, `"`)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
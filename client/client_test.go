@@ -0,0 +1,162 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ananaslegend/jrpc"
+	"github.com/ananaslegend/jrpc/client"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	router := jrpc.NewHTTPRouter(":8080")
+
+	jrpc.Register(router.Router, "subtract", func(ctx context.Context, p [2]int) (int, error) {
+		return p[0] - p[1], nil
+	})
+
+	router.Method("fail", func(ctx context.Context) (any, error) {
+		return nil, jrpc.InvalidParamsError("bad params")
+	})
+
+	router.Method("update", func(ctx context.Context) (any, error) {
+		return nil, nil
+	})
+
+	router.Method("validate", func(ctx context.Context) (any, error) {
+		return nil, jrpc.NewError(jrpc.CodeInvalidParams, "Invalid params", []fieldError{{Field: "name", Reason: "required"}})
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(router.Handle))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func Test_Client_Call(t *testing.T) {
+	srv := newTestServer(t)
+	c := client.New(srv.URL)
+
+	var res int
+	if err := c.Call(context.Background(), "subtract", []int{42, 23}, &res); err != nil {
+		t.Fatal(err)
+	}
+
+	if res != 19 {
+		t.Errorf("got %d, want 19", res)
+	}
+
+	err := c.Call(context.Background(), "fail", nil, nil)
+
+	var jrpcErr *jrpc.Error
+	if !errors.As(err, &jrpcErr) || jrpcErr.Code != -32602 || jrpcErr.Message != "bad params" {
+		t.Errorf("got %v, want invalid params error", err)
+	}
+
+	if err = c.Notify(context.Background(), "update", []int{1, 2}); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+}
+
+func Test_Client_Batch(t *testing.T) {
+	srv := newTestServer(t)
+	c := client.New(srv.URL)
+
+	var first, second int
+
+	batch := c.NewBatch()
+	firstCall := batch.Call("subtract", []int{42, 23}, &first)
+	secondCall := batch.Call("subtract", []int{23, 42}, &second)
+	failCall := batch.Call("fail", nil, nil)
+	missingCall := batch.Call("missing", nil, nil)
+	batch.Notify("update", nil)
+
+	if err := batch.Send(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if firstCall.Err() != nil || first != 19 {
+		t.Errorf("got (%d, %v), want (19, nil)", first, firstCall.Err())
+	}
+
+	if secondCall.Err() != nil || second != -19 {
+		t.Errorf("got (%d, %v), want (-19, nil)", second, secondCall.Err())
+	}
+
+	var jrpcErr *jrpc.Error
+	if !errors.As(failCall.Err(), &jrpcErr) || jrpcErr.Code != -32602 {
+		t.Errorf("got %v, want invalid params error", failCall.Err())
+	}
+
+	if !errors.As(missingCall.Err(), &jrpcErr) || jrpcErr.Code != -32601 {
+		t.Errorf("got %v, want method not found error", missingCall.Err())
+	}
+}
+
+type fieldError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+func Test_Client_ErrorData(t *testing.T) {
+	srv := newTestServer(t)
+	c := client.New(srv.URL)
+
+	err := c.Call(context.Background(), "validate", nil, nil)
+	if !errors.Is(err, jrpc.InvalidParamsError()) {
+		t.Fatalf("got %v, want invalid params error", err)
+	}
+
+	data, dataErr := jrpc.ErrorData[[]fieldError](err)
+	if dataErr != nil {
+		t.Fatal(dataErr)
+	}
+
+	if len(data) != 1 || data[0] != (fieldError{Field: "name", Reason: "required"}) {
+		t.Errorf("got %+v, want name field error", data)
+	}
+
+	if _, dataErr = jrpc.ErrorData[[]fieldError](c.Call(context.Background(), "fail", nil, nil)); !errors.Is(dataErr, jrpc.ErrNoErrorData) {
+		t.Errorf("got %v, want %v", dataErr, jrpc.ErrNoErrorData)
+	}
+}
+
+func Test_Client_Call_ResponseID(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		check    func(err error) bool
+	}{
+		{
+			name:     "other id",
+			response: `{"jsonrpc": "2.0", "result": 19, "id": 42}`,
+			check:    func(err error) bool { return errors.Is(err, client.ErrInvalidResponse) },
+		},
+		{
+			name:     "result with null id",
+			response: `{"jsonrpc": "2.0", "result": 19, "id": null}`,
+			check:    func(err error) bool { return errors.Is(err, client.ErrInvalidResponse) },
+		},
+		{
+			name:     "error with null id",
+			response: `{"jsonrpc": "2.0", "error": {"code": -32700, "message": "Parse error"}, "id": null}`,
+			check:    func(err error) bool { return errors.Is(err, jrpc.ParseError()) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer srv.Close()
+
+			var res int
+			if err := client.New(srv.URL).Call(context.Background(), "subtract", []int{42, 23}, &res); !tt.check(err) {
+				t.Errorf("got %v", err)
+			}
+		})
+	}
+}
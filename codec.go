@@ -0,0 +1,137 @@
+package jrpc
+
+import (
+	"bytes"
+	"context"
+	stdjson "encoding/json"
+
+	"github.com/goccy/go-json"
+)
+
+// Codec encodes results, error data and notifications params, and decodes request params.
+// Request envelope is always parsed by the router itself.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+type CodecOption func(*codecOptions)
+
+type codecOptions struct {
+	useNumber             bool
+	disallowUnknownFields bool
+}
+
+// UseNumber makes the codec decode numbers into interface{} as json.Number instead of float64.
+func UseNumber() CodecOption {
+	return func(opts *codecOptions) {
+		opts.useNumber = true
+	}
+}
+
+// DisallowUnknownFields makes the codec fail on object keys that don't match any field of the destination struct.
+func DisallowUnknownFields() CodecOption {
+	return func(opts *codecOptions) {
+		opts.disallowUnknownFields = true
+	}
+}
+
+var defaultCodec = NewJSONCodec()
+
+func WithCodec(codec Codec) RouterOption {
+	return func(router *engine) {
+		router.codec = codec
+	}
+}
+
+type jsonCodec struct {
+	opts codecOptions
+}
+
+// NewJSONCodec returns codec based on github.com/goccy/go-json, it is used by default.
+func NewJSONCodec(opts ...CodecOption) Codec {
+	codec := &jsonCodec{}
+
+	for _, opt := range opts {
+		opt(&codec.opts)
+	}
+
+	return codec
+}
+
+func (codec *jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.MarshalNoEscape(v)
+}
+
+func (codec *jsonCodec) Unmarshal(data []byte, v any) error {
+	if !codec.opts.useNumber && !codec.opts.disallowUnknownFields {
+		return json.Unmarshal(data, v)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	if codec.opts.useNumber {
+		dec.UseNumber()
+	}
+
+	if codec.opts.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	return dec.Decode(v)
+}
+
+type stdJSONCodec struct {
+	opts codecOptions
+}
+
+// NewStdJSONCodec returns codec based on encoding/json, for strict compatibility with the standard library.
+func NewStdJSONCodec(opts ...CodecOption) Codec {
+	codec := &stdJSONCodec{}
+
+	for _, opt := range opts {
+		opt(&codec.opts)
+	}
+
+	return codec
+}
+
+func (codec *stdJSONCodec) Marshal(v any) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	enc := stdjson.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	// Encode terminates the value with a newline.
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
+
+func (codec *stdJSONCodec) Unmarshal(data []byte, v any) error {
+	if !codec.opts.useNumber && !codec.opts.disallowUnknownFields {
+		return stdjson.Unmarshal(data, v)
+	}
+
+	dec := stdjson.NewDecoder(bytes.NewReader(data))
+
+	if codec.opts.useNumber {
+		dec.UseNumber()
+	}
+
+	if codec.opts.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	return dec.Decode(v)
+}
+
+func codecFromContext(ctx context.Context) Codec {
+	if info, ok := getCallInfo(ctx); ok && info.codec != nil {
+		return info.codec
+	}
+
+	return defaultCodec
+}
@@ -0,0 +1,75 @@
+package jrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+func Test_Codec(t *testing.T) {
+	tests := []struct {
+		name    string
+		codec   jrpc.Codec
+		request []byte
+		result  []byte
+	}{
+		{
+			name:    "default codec ignores unknown fields",
+			codec:   jrpc.NewJSONCodec(),
+			request: []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": {"subtrahend": 23, "minuend": 42, "foo": 1}, "id": 1}`),
+			result:  []byte(`{"jsonrpc": "2.0", "result": 19, "id": 1}`),
+		},
+		{
+			name:    "std codec with disallowed unknown fields",
+			codec:   jrpc.NewStdJSONCodec(jrpc.DisallowUnknownFields()),
+			request: []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": {"subtrahend": 23, "minuend": 42, "foo": 1}, "id": 1}`),
+			result:  []byte(`{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params"}, "id": 1}`),
+		},
+		{
+			name:    "goccy codec with disallowed unknown fields",
+			codec:   jrpc.NewJSONCodec(jrpc.DisallowUnknownFields()),
+			request: []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": {"subtrahend": 23, "minuend": 42, "foo": 1}, "id": 1}`),
+			result:  []byte(`{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params"}, "id": 1}`),
+		},
+		{
+			name:    "std codec with use number",
+			codec:   jrpc.NewStdJSONCodec(jrpc.UseNumber()),
+			request: []byte(`{"jsonrpc": "2.0", "method": "number", "params": [12345678901234567890], "id": 1}`),
+			result:  []byte(`{"jsonrpc": "2.0", "result": "12345678901234567890", "id": 1}`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := jrpc.NewRouter()
+			router.Configure(jrpc.WithCodec(tt.codec))
+
+			router.Method(namedSubtractHandler.method, namedSubtractHandler.handlerFunc)
+			router.Method("number", func(ctx context.Context) (any, error) {
+				p, err := jrpc.ParamsTo[[]any](ctx)
+				if err != nil {
+					return nil, err
+				}
+
+				n, ok := (*p)[0].(json.Number)
+				if !ok {
+					return nil, jrpc.InvalidParamsError()
+				}
+
+				return n.String(), nil
+			})
+
+			result := router.Handle(context.Background(), tt.request)
+
+			equals, err := resultsEquals(string(result), string(tt.result))
+			if err != nil {
+				t.Errorf("error comparing results: %s", err.Error())
+			}
+
+			if !equals {
+				t.Errorf("got %s, want %s", string(result), string(tt.result))
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package jrpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrNoConnection       = errors.New("jrpc: no persistent connection in context")
+	ErrConnectionClosed   = errors.New("jrpc: connection closed")
+	ErrSubscriptionClosed = errors.New("jrpc: subscription closed")
+)
+
+type connKey struct{}
+
+func setConnection(ctx context.Context, conn *Connection) context.Context {
+	return context.WithValue(ctx, connKey{}, conn)
+}
+
+// Conn returns the persistent connection of the request, or nil if the request came from stateless transport such as HTTP.
+func Conn(ctx context.Context) *Connection {
+	conn, _ := ctx.Value(connKey{}).(*Connection)
+
+	return conn
+}
+
+type Connection struct {
+	ctx   context.Context
+	write func(msg []byte) error
+	codec Codec
+
+	mu            sync.Mutex
+	subscriptions map[string]*Subscription
+}
+
+func newConnection(ctx context.Context, codec Codec, write func(msg []byte) error) *Connection {
+	return &Connection{
+		ctx:           ctx,
+		write:         write,
+		codec:         codec,
+		subscriptions: make(map[string]*Subscription),
+	}
+}
+
+type notification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// Notify sends a server-initiated notification to the client.
+func (c *Connection) Notify(method string, params any) error {
+	if c.ctx.Err() != nil {
+		return ErrConnectionClosed
+	}
+
+	msg, err := c.codec.Marshal(notification{JSONRPC: "2.0", Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+
+	return c.write(msg)
+}
+
+// Done is closed when the connection is closed.
+func (c *Connection) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
+
+// Unsubscribe cancels the subscription with passed id, it returns false if there is no such subscription.
+func (c *Connection) Unsubscribe(id string) bool {
+	c.mu.Lock()
+	sub, ok := c.subscriptions[id]
+	c.mu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	sub.Unsubscribe()
+
+	return true
+}
+
+type Subscription struct {
+	ID string
+
+	method string
+	conn   *Connection
+
+	// events are held until the response with the subscription id is written
+	mu      sync.Mutex
+	held    bool
+	pending []any
+
+	done chan struct{}
+	once sync.Once
+}
+
+type responseScopeKey struct{}
+
+// responseScope holds subscriptions created while handling a message until the response of the message is written.
+type responseScope struct {
+	mu      sync.Mutex
+	written bool
+	subs    []*Subscription
+}
+
+func withResponseScope(ctx context.Context) (context.Context, *responseScope) {
+	scope := &responseScope{}
+
+	return context.WithValue(ctx, responseScopeKey{}, scope), scope
+}
+
+// hold adds the subscription to the scope, it returns false if the response is already written.
+func (scope *responseScope) hold(sub *Subscription) bool {
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+
+	if scope.written {
+		return false
+	}
+
+	scope.subs = append(scope.subs, sub)
+
+	return true
+}
+
+// release is called after the response is written, it sends held events of the subscriptions.
+func (scope *responseScope) release() {
+	scope.mu.Lock()
+	scope.written = true
+	subs := scope.subs
+	scope.subs = nil
+	scope.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.activate()
+	}
+}
+
+type subscriptionResult struct {
+	Subscription string `json:"subscription"`
+	Result       any    `json:"result"`
+}
+
+// NewSubscription creates a subscription on the connection of the request. Events are sent as notifications
+// of passed method with {"subscription": id, "result": event} params, until the client unsubscribes or disconnects.
+func NewSubscription(ctx context.Context, method string) (*Subscription, error) {
+	conn := Conn(ctx)
+	if conn == nil {
+		return nil, ErrNoConnection
+	}
+
+	if conn.ctx.Err() != nil {
+		return nil, ErrConnectionClosed
+	}
+
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, err
+	}
+
+	sub := &Subscription{
+		ID:     "0x" + hex.EncodeToString(idBytes),
+		method: method,
+		conn:   conn,
+		done:   make(chan struct{}),
+	}
+
+	if scope, ok := ctx.Value(responseScopeKey{}).(*responseScope); ok {
+		sub.held = true
+		if !scope.hold(sub) {
+			sub.held = false
+		}
+	}
+
+	conn.mu.Lock()
+	conn.subscriptions[sub.ID] = sub
+	conn.mu.Unlock()
+
+	go func() {
+		select {
+		case <-conn.ctx.Done():
+			sub.Unsubscribe()
+		case <-sub.done:
+		}
+	}()
+
+	return sub, nil
+}
+
+// Notify sends the event to the client. Events sent before the response with the subscription id is written
+// are held and sent right after it.
+func (s *Subscription) Notify(result any) error {
+	select {
+	case <-s.done:
+		return ErrSubscriptionClosed
+	default:
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.held {
+		s.pending = append(s.pending, result)
+
+		return nil
+	}
+
+	return s.conn.Notify(s.method, subscriptionResult{Subscription: s.ID, Result: result})
+}
+
+func (s *Subscription) activate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, result := range s.pending {
+		if err := s.conn.Notify(s.method, subscriptionResult{Subscription: s.ID, Result: result}); err != nil {
+			break
+		}
+	}
+
+	s.held, s.pending = false, nil
+}
+
+// Done is closed when the client unsubscribes or disconnects.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Subscription) Unsubscribe() {
+	s.once.Do(func() {
+		s.conn.mu.Lock()
+		delete(s.conn.subscriptions, s.ID)
+		s.conn.mu.Unlock()
+
+		close(s.done)
+	})
+}
@@ -3,23 +3,56 @@ package jrpc
 import (
 	"bytes"
 	"context"
-	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"reflect"
+	"slices"
+	"time"
 
-	"github.com/goccy/go-json"
 	"github.com/valyala/fastjson"
 )
 
 type handler struct {
-	handlerFunc func(ctx context.Context) (any, error)
+	handlerFunc HandlerFunc
 	dontRender  bool
+
+	timeout time.Duration
+
+	paramsSchema Schema
+
+	paramsType reflect.Type
+	resultType reflect.Type
+
+	summary     string
+	description string
 }
 
 type engine struct {
 	handlersMap map[string]*handler
 
+	debug bool
+
+	executor         *executor
+	batchParallelism int
+	orderedBatch     bool
+	sequentialBatch  bool
+
+	timeout      time.Duration
+	batchTimeout time.Duration
+
+	validation ValidationMode
+
+	codec Codec
+
+	errorMappers []errorMapper
+	production   bool
+
+	limits limits
+
+	lifecycle *lifecycle
+
 	logger          *slog.Logger
 	logRequestFunc  func(req []byte, logger *slog.Logger)
 	logNotFoundFunc func(method string, logger *slog.Logger)
@@ -39,7 +72,11 @@ func (router *engine) logNotFound(method string) {
 
 func newEngine(logger ...*slog.Logger) *engine {
 	r := &engine{
-		handlersMap: make(map[string]*handler),
+		handlersMap:      make(map[string]*handler),
+		executor:         &executor{},
+		batchParallelism: defaultBatchParallelism,
+		codec:            defaultCodec,
+		lifecycle:        newLifecycle(),
 	}
 
 	if len(logger) > 0 {
@@ -62,67 +99,197 @@ func (router *engine) handleMethod(method string, h *handler) {
 }
 
 func (router *engine) handle(ctx context.Context, bts []byte) []byte {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	router.handleInto(ctx, buf, bts, true)
+
+	if buf.Len() == 0 {
+		return nil
+	}
+
+	return bytes.Clone(buf.Bytes())
+}
+
+// handleTranslated handles the request translated into JSON from binary encoding. The size limit is checked
+// by the transport on the wire bytes, as JSON translation is usually larger.
+func (router *engine) handleTranslated(ctx context.Context, bts []byte) []byte {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	router.handleInto(ctx, buf, bts, false)
+
+	if buf.Len() == 0 {
+		return nil
+	}
+
+	return bytes.Clone(buf.Bytes())
+}
+
+func (router *engine) handleTo(ctx context.Context, w io.Writer, bts []byte) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	router.handleInto(ctx, buf, bts, true)
+
+	return writeResponse(w, buf)
+}
+
+func (router *engine) handleInto(ctx context.Context, buf *bytes.Buffer, bts []byte, checkSize bool) {
 	router.logRequest(bts)
 
+	if limitErr := router.limits.checkRequest(bts, checkSize); limitErr != nil {
+		router.renderError(buf, limitErr)
+
+		return
+	}
+
 	arr, isButch, err := getRequestsArr(bts)
 	if err != nil {
-		return errorParsingJSONString
+		buf.Write(errorParsingJSONString)
+
+		return
 	}
 
 	if len(arr) == 0 {
-		return errorInvalidRequest
+		buf.Write(errorInvalidRequest)
+
+		return
 	}
 
-	jobs, resultCh := workerPoolWithResult[*result](ctx, len(arr))
+	if router.limits.maxBatchLength > 0 && len(arr) > router.limits.maxBatchLength {
+		router.renderError(buf, batchTooLargeError(router.limits.maxBatchLength))
+
+		return
+	}
 
-	for _, reqValue := range arr {
-		jobs <- func() *result {
-			id := getRequestID(reqValue)
+	callCtx := ctx
 
-			ctx = setRequestID(ctx, id)
+	if isButch && router.batchTimeout > 0 {
+		var cancel context.CancelFunc
 
-			if !reqValue.Exists("method") {
-				id.renderNull = true
-				return &result{Err: InvalidRequestError(), Id: id}
+		callCtx, cancel = context.WithTimeout(ctx, router.batchTimeout)
+		defer cancel()
+	}
+
+	workers := router.batchWorkers(len(arr))
+
+	// DontRender members of the batch running in background are bounded by the batch parallelism as well
+	var detachedSlots chan struct{}
+	if isButch {
+		detachedSlots = make(chan struct{}, workers)
+	}
+
+	jobs, resultCh := workerPoolWithResult[*result](ctx, workers)
+
+	go func() {
+		defer close(jobs)
+
+		for i, reqValue := range arr {
+			job := func() *result {
+				res := router.handleCall(callCtx, reqValue, i, isButch, detachedSlots)
+				if res != nil {
+					res.index = i
+				}
+
+				return res
 			}
 
-			method := string(reqValue.GetStringBytes("method"))
-			if method == "" {
-				return &result{Err: InvalidRequestError(), Id: id}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return
 			}
+		}
+	}()
 
-			ctx = setParams(ctx, reqValue)
+	resultList := make([]result, 0, len(arr))
 
-			h, ok := router.handlersMap[method]
-			if !ok {
-				router.logNotFound(method)
+	for res := range resultCh {
+		if res != nil {
+			resultList = append(resultList, *res)
+		}
+	}
 
-				return processResult(id, MethodNotFoundError(), nil)
-			}
+	if router.orderedBatch || router.sequentialBatch {
+		slices.SortFunc(resultList, func(a, b result) int {
+			return a.index - b.index
+		})
+	}
+
+	renderResponse(buf, router.codec, resultList, isButch, router.mapError)
+}
 
-			if h.dontRender || id == nil {
-				go h.handlerFunc(ctx)
+func (router *engine) handleCall(ctx context.Context, reqValue *fastjson.Value, batchIndex int, isBatch bool, detachedSlots chan struct{}) *result {
+	id := getRequestID(reqValue)
 
-				return nil
+	if router.validation == ValidationStrict {
+		if reason := validateRequest(reqValue); reason != "" {
+			if !validRequestID(reqValue) {
+				id = &requestID{}
 			}
 
-			res, err := h.handlerFunc(ctx)
+			id.renderNull = true
+
+			invalidRequestErr := InvalidRequestError()
+			invalidRequestErr.Data = reason
 
-			return processResult(id, err, res)
+			return &result{Err: invalidRequestErr, Id: id}
 		}
 	}
 
-	close(jobs)
+	callCtx := setRequestID(ctx, id)
 
-	resultList := make([]result, 0, len(arr))
+	if !reqValue.Exists("method") {
+		id.renderNull = true
+		return &result{Err: InvalidRequestError(), Id: id}
+	}
 
-	for res := range resultCh {
-		if res != nil {
-			resultList = append(resultList, *res)
+	method := string(reqValue.GetStringBytes("method"))
+	if method == "" {
+		return &result{Err: InvalidRequestError(), Id: id}
+	}
+
+	callCtx = setParams(callCtx, reqValue)
+
+	if limit := router.limits.maxParamsSize; limit > 0 && len(Params(callCtx)) > limit {
+		return router.processResult(method, id, paramsTooLargeError(limit), nil)
+	}
+	callCtx = setCallInfo(callCtx, callInfo{
+		method:         method,
+		batchIndex:     batchIndex,
+		isBatch:        isBatch,
+		isNotification: !id.notNull,
+		codec:          router.codec,
+	})
+
+	h, ok := router.handlersMap[method]
+	if !ok {
+		router.logNotFound(method)
+
+		return router.processResult(method, id, MethodNotFoundError(), nil)
+	}
+
+	if h.dontRender {
+		// members of sequential batch run inline, so later members see their side effects
+		if router.sequentialBatch && isBatch {
+			_, _ = router.execute(callCtx, h)
+
+			return nil
 		}
+
+		router.executeDetached(callCtx, h, detachedSlots)
+
+		return nil
 	}
 
-	return renderResponse(resultList, isButch)
+	res, err := router.execute(callCtx, h)
+
+	return router.processResult(method, id, err, res)
+}
+
+func (router *engine) renderError(buf *bytes.Buffer, err *Error) {
+	renderResponse(buf, router.codec, []result{{Err: err, Id: &requestID{}}}, false, router.mapError)
 }
 
 func getRequestsArr(body []byte) ([]*fastjson.Value, bool, error) {
@@ -141,81 +308,36 @@ func getRequestsArr(body []byte) ([]*fastjson.Value, bool, error) {
 	return requestsArr, true, nil
 }
 
-func processResult(id *requestID, err error, res any) *result {
+func (router *engine) processResult(method string, id *requestID, err error, res any) *result {
 	if id != nil {
 		if !id.notNull && !id.renderNull { // todo
 			return nil
 		}
 
 		if err != nil {
-			var jrpcErr *Error
-			if errors.As(err, &jrpcErr) {
-				return &result{Err: jrpcErr, Id: id}
-			}
-
-			return &result{Err: InternalError(err.Error()), Id: id}
+			return &result{Err: router.mapError(method, err), Id: id}
 		}
 
-		return &result{Err: nil, Res: res, Id: id}
+		return &result{Err: nil, Res: res, Id: id, method: method}
 	}
 
 	return nil
 }
 
-func renderResponse(results []result, isButch bool) []byte {
-	if len(results) == 0 {
-		return nil
-	}
-
-	resWriter := &bytes.Buffer{}
-
-	if isButch {
-		resWriter.WriteRune('[')
-	}
-
-	var firstRendered bool
-
-	for _, res := range results {
-		if firstRendered {
-			resWriter.WriteRune(',')
-		}
-
-		resWriter.Write(res.RenderJSON())
-		firstRendered = true
-
-	}
-
-	if isButch {
-		resWriter.WriteRune(']')
-	}
-
-	return resWriter.Bytes()
-}
-
 func (router *engine) handleRequest(ctx context.Context, method string) (any, error) {
 	h, ok := router.handlersMap[method]
 	if !ok {
 		return nil, MethodNotFoundError()
 	}
 
-	return h.handlerFunc(ctx)
+	return router.execute(ctx, h)
 }
 
 type result struct {
 	Err *Error     `json:"error"`
 	Res any        `json:"result"`
 	Id  *requestID `json:"id"`
-}
-
-func (r result) RenderJSON() []byte {
-	if r.Err != nil {
-		return []byte(fmt.Sprintf(`{"jsonrpc": "2.0", "error": %v, "id": %v}`, r.Err, r.Id))
-	}
-
-	resultJSON, err := json.Marshal(r.Res)
-	if err != nil {
-		return []byte(fmt.Sprintf(`{"jsonrpc": "2.0", "error": %v, "id": %v}`, InternalError("error during marshaling result: "+err.Error()), r.Id))
-	}
 
-	return []byte(fmt.Sprintf(`{"jsonrpc": "2.0", "result": %s, "id": %v}`, resultJSON, r.Id))
+	index  int
+	method string
 }
@@ -0,0 +1,44 @@
+package jrpc
+
+import (
+	"errors"
+
+	"github.com/goccy/go-json"
+)
+
+var ErrNoErrorData = errors.New("jrpc: error has no data")
+
+// NewError returns the error with typed data, the data is encoded with the router codec.
+func NewError[T any](code int, msg string, data T) *Error {
+	return &Error{Code: code, Message: msg, Data: data}
+}
+
+// ErrorData decodes Data of *Error found in the chain of err into T. Data of errors received by the client
+// is kept as raw JSON and decoded here, data of other type is converted through JSON.
+// It returns ErrNoErrorData if there is no *Error in the chain or it has no data.
+func ErrorData[T any](err error) (T, error) {
+	var data T
+
+	var jrpcErr *Error
+	if !errors.As(err, &jrpcErr) || jrpcErr.Data == nil {
+		return data, ErrNoErrorData
+	}
+
+	if typed, ok := jrpcErr.Data.(T); ok {
+		return typed, nil
+	}
+
+	raw, ok := jrpcErr.Data.(json.RawMessage)
+	if !ok {
+		var marshalErr error
+		if raw, marshalErr = defaultCodec.Marshal(jrpcErr.Data); marshalErr != nil {
+			return data, marshalErr
+		}
+	}
+
+	if err := defaultCodec.Unmarshal(raw, &data); err != nil {
+		return data, err
+	}
+
+	return data, nil
+}
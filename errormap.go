@@ -0,0 +1,64 @@
+package jrpc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errorMapper converts the handler error into JSON-RPC error, it returns nil if the error doesn't match.
+type errorMapper func(err error) *Error
+
+// MapError maps handler errors matching the target by errors.Is into JSON-RPC errors.
+// Mappers are checked in registration order, the first matched one is used.
+func (r *Router) MapError(target error, mapFunc func(err error) *Error) {
+	r.engine.errorMappers = append(r.engine.errorMappers, func(err error) *Error {
+		if !errors.Is(err, target) {
+			return nil
+		}
+
+		return mapFunc(err)
+	})
+}
+
+// MapErrorAs maps handler errors having T in their chain by errors.As into JSON-RPC errors.
+// Mappers are checked in registration order, the first matched one is used.
+func MapErrorAs[T error](r *Router, mapFunc func(err T) *Error) {
+	r.engine.errorMappers = append(r.engine.errorMappers, func(err error) *Error {
+		var target T
+		if !errors.As(err, &target) {
+			return nil
+		}
+
+		return mapFunc(target)
+	})
+}
+
+// WithProductionMode hides messages of unmapped handler errors and errors of marshaling results
+// behind generic Internal error, the original error is logged with the router logger.
+func WithProductionMode() RouterOption {
+	return func(router *engine) {
+		router.production = true
+	}
+}
+
+// mapError converts the handler error into JSON-RPC error.
+func (router *engine) mapError(method string, err error) *Error {
+	var jrpcErr *Error
+	if errors.As(err, &jrpcErr) {
+		return jrpcErr
+	}
+
+	for _, mapper := range router.errorMappers {
+		if mappedErr := mapper(err); mappedErr != nil {
+			return mappedErr
+		}
+	}
+
+	if router.production {
+		router.logger.Error(fmt.Sprintf("error during handling request: %v", err.Error()), "method", method)
+
+		return InternalError().WithCause(err)
+	}
+
+	return InternalError(err.Error()).WithCause(err)
+}
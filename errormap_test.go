@@ -0,0 +1,109 @@
+package jrpc_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+var errNotFound = errors.New("user not found")
+
+type quotaError struct {
+	Limit int
+}
+
+func (e *quotaError) Error() string {
+	return fmt.Sprintf("quota %d exceeded", e.Limit)
+}
+
+func Test_MapError(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   []jrpc.RouterOption
+		err    error
+		res    any
+		result string
+		logged string
+	}{
+		{
+			name:   "mapped by errors.Is",
+			err:    fmt.Errorf("get user: %w", errNotFound),
+			result: `{"jsonrpc": "2.0", "error": {"code": -32004, "message": "Not found", "data": "get user: user not found"}, "id": 1}`,
+		},
+		{
+			name:   "mapped by errors.As",
+			err:    fmt.Errorf("create user: %w", &quotaError{Limit: 10}),
+			result: `{"jsonrpc": "2.0", "error": {"code": -32005, "message": "Quota exceeded", "data": {"limit": 10}}, "id": 1}`,
+		},
+		{
+			name:   "jrpc error is not mapped",
+			err:    jrpc.InvalidParamsError(),
+			result: `{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params"}, "id": 1}`,
+		},
+		{
+			name:   "unmapped error",
+			err:    errors.New("db: connection refused"),
+			result: `{"jsonrpc": "2.0", "error": {"code": -32603, "message": "db: connection refused"}, "id": 1}`,
+		},
+		{
+			name:   "unmapped error in production mode",
+			opts:   []jrpc.RouterOption{jrpc.WithProductionMode()},
+			err:    errors.New("db: connection refused"),
+			result: `{"jsonrpc": "2.0", "error": {"code": -32603, "message": "Internal error"}, "id": 1}`,
+			logged: "db: connection refused",
+		},
+		{
+			name:   "mapped error in production mode",
+			opts:   []jrpc.RouterOption{jrpc.WithProductionMode()},
+			err:    errNotFound,
+			result: `{"jsonrpc": "2.0", "error": {"code": -32004, "message": "Not found", "data": "user not found"}, "id": 1}`,
+		},
+		{
+			name:   "marshaling result error in production mode",
+			opts:   []jrpc.RouterOption{jrpc.WithProductionMode()},
+			res:    make(chan int),
+			result: `{"jsonrpc": "2.0", "error": {"code": -32603, "message": "Internal error"}, "id": 1}`,
+			logged: "error during marshaling result",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := &bytes.Buffer{}
+
+			router := jrpc.NewRouter(slog.New(slog.NewTextHandler(logs, nil)))
+			router.Configure(tt.opts...)
+
+			router.MapError(errNotFound, func(err error) *jrpc.Error {
+				return &jrpc.Error{Code: -32004, Message: "Not found", Data: err.Error()}
+			})
+			jrpc.MapErrorAs(router, func(err *quotaError) *jrpc.Error {
+				return &jrpc.Error{Code: -32005, Message: "Quota exceeded", Data: map[string]int{"limit": err.Limit}}
+			})
+
+			router.Method("fail", func(ctx context.Context) (any, error) {
+				return tt.res, tt.err
+			})
+
+			result := router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "fail", "id": 1}`))
+
+			equals, err := resultsEquals(string(result), tt.result)
+			if err != nil {
+				t.Errorf("error comparing results: %s", err.Error())
+			}
+
+			if !equals {
+				t.Errorf("got %s, want %s", string(result), tt.result)
+			}
+
+			if tt.logged != "" && !strings.Contains(logs.String(), tt.logged) {
+				t.Errorf("original error %q is not logged: %s", tt.logged, logs.String())
+			}
+		})
+	}
+}
@@ -1,46 +1,108 @@
 package jrpc
 
 import (
+	"bytes"
 	"fmt"
+	"strconv"
 
 	"github.com/goccy/go-json"
 )
 
 var (
-	errorParsingJSONString = []byte(`{"jsonrpc": "2.0", "error": {"code": -32700, "message": "Parse error"}, "id": null}`)
-	errorInvalidRequest    = []byte(`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request"}, "id": null}`)
+	errorParsingJSONString = []byte(`{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error"},"id":null}`)
+	errorInvalidRequest    = []byte(`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request"},"id":null}`)
+)
+
+// Codes of errors defined by the specification and errors of the server error range used by the package.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+
+	CodeServerBusy     = -32000
+	CodeRequestTimeout = -32001
+
+	// MinServerErrorCode and MaxServerErrorCode bound the range reserved for implementation-defined server errors.
+	MinServerErrorCode = -32099
+	MaxServerErrorCode = -32000
 )
 
 type Error struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Data    any    `json:"data"`
+
+	cause error
 }
 
+// Error returns human-readable description of the error with its cause.
 func (e *Error) Error() string {
-	str := fmt.Sprintf(`{"code": %d, "message": "%s"`, e.Code, e.Message)
+	msg := "jrpc: code " + strconv.Itoa(e.Code) + ": " + e.Message
 
-	data := func() string {
-		if e.Data != nil {
-			jsonData, err := json.Marshal(e.Data)
-			if err != nil {
-				jsonData = []byte("error during marshaling error data: " + err.Error())
-			}
+	if e.cause != nil {
+		msg += ": " + e.cause.Error()
+	}
 
-			return fmt.Sprintf(`, "data": %s`, string(jsonData))
-		}
-		return ""
-	}()
+	return msg
+}
 
-	str += data
+// Is reports whether the target is *Error with the same code, so errors.Is(err, jrpc.MethodNotFoundError())
+// matches any Method not found error.
+func (e *Error) Is(target error) bool {
+	jrpcErr, ok := target.(*Error)
+	if !ok || jrpcErr == nil {
+		return false
+	}
 
-	str += "}"
+	return e.Code == jrpcErr.Code
+}
 
-	return str
+// Unwrap returns the cause of the error, it is never sent to the client.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// WithCause sets the cause of the error and returns the error.
+func (e *Error) WithCause(cause error) *Error {
+	e.cause = cause
+
+	return e
+}
+
+func (e *Error) MarshalJSON() ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	e.writeJSON(buf, defaultCodec)
+
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// UnmarshalJSON decodes the error keeping Data as raw JSON, so it can be decoded into its type by ErrorData.
+func (e *Error) UnmarshalJSON(bts []byte) error {
+	var decoded struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+
+	if err := defaultCodec.Unmarshal(bts, &decoded); err != nil {
+		return err
+	}
+
+	e.Code, e.Message, e.Data = decoded.Code, decoded.Message, nil
+
+	if len(decoded.Data) != 0 && string(decoded.Data) != "null" {
+		e.Data = decoded.Data
+	}
+
+	return nil
 }
 
 func ParseError(msg ...string) *Error {
-	err := &Error{Code: -32700, Message: "Parse error"}
+	err := &Error{Code: CodeParseError, Message: "Parse error"}
 
 	if len(msg) != 0 {
 		err.Message = msg[0]
@@ -50,7 +112,7 @@ func ParseError(msg ...string) *Error {
 }
 
 func InvalidRequestError(msg ...string) *Error {
-	err := &Error{Code: -32600, Message: "Invalid Request"}
+	err := &Error{Code: CodeInvalidRequest, Message: "Invalid Request"}
 
 	if len(msg) != 0 {
 		err.Message = msg[0]
@@ -60,11 +122,11 @@ func InvalidRequestError(msg ...string) *Error {
 }
 
 func MethodNotFoundError() *Error {
-	return &Error{Code: -32601, Message: "Method not found"}
+	return &Error{Code: CodeMethodNotFound, Message: "Method not found"}
 }
 
 func InvalidParamsError(msg ...string) *Error {
-	err := &Error{Code: -32602, Message: "Invalid params"}
+	err := &Error{Code: CodeInvalidParams, Message: "Invalid params"}
 
 	if len(msg) != 0 {
 		err.Message = msg[0]
@@ -74,7 +136,7 @@ func InvalidParamsError(msg ...string) *Error {
 }
 
 func InternalError(msg ...string) *Error {
-	err := &Error{Code: -32603, Message: "Internal error"}
+	err := &Error{Code: CodeInternalError, Message: "Internal error"}
 
 	if len(msg) != 0 {
 		err.Message = msg[0]
@@ -82,3 +144,22 @@ func InternalError(msg ...string) *Error {
 
 	return err
 }
+
+func ServerBusyError() *Error {
+	return &Error{Code: CodeServerBusy, Message: "Server busy"}
+}
+
+func RequestTimeoutError() *Error {
+	return &Error{Code: CodeRequestTimeout, Message: "Request timeout"}
+}
+
+// ServerError returns implementation-defined server error, the code must be in range -32099..-32000.
+// Code out of the range is a programming error, Internal error is returned then, with the cause describing it.
+func ServerError(code int, msg string) *Error {
+	if code < MinServerErrorCode || code > MaxServerErrorCode {
+		return InternalError().WithCause(fmt.Errorf("jrpc: server error code %d is out of range %d..%d: %s",
+			code, MinServerErrorCode, MaxServerErrorCode, msg))
+	}
+
+	return &Error{Code: code, Message: msg}
+}
@@ -0,0 +1,148 @@
+package jrpc_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+func Test_Error_Is(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		{
+			name:   "same code",
+			err:    jrpc.MethodNotFoundError(),
+			target: jrpc.MethodNotFoundError(),
+			want:   true,
+		},
+		{
+			name:   "same code with other message",
+			err:    jrpc.InternalError("db is down"),
+			target: jrpc.InternalError(),
+			want:   true,
+		},
+		{
+			name:   "wrapped",
+			err:    fmt.Errorf("call: %w", jrpc.InvalidParamsError()),
+			target: jrpc.InvalidParamsError(),
+			want:   true,
+		},
+		{
+			name:   "other code",
+			err:    jrpc.MethodNotFoundError(),
+			target: jrpc.InvalidParamsError(),
+			want:   false,
+		},
+		{
+			name:   "not jrpc error",
+			err:    jrpc.InternalError(),
+			target: errors.New("Internal error"),
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Error_Cause(t *testing.T) {
+	cause := errors.New("connection refused")
+
+	err := jrpc.InternalError().WithCause(cause)
+
+	if !errors.Is(err, cause) {
+		t.Error("error doesn't match its cause")
+	}
+
+	want := "jrpc: code -32603: Internal error: connection refused"
+	if err.Error() != want {
+		t.Errorf("got %q, want %q", err.Error(), want)
+	}
+
+	bts, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+
+	if string(bts) != `{"code":-32603,"message":"Internal error"}` {
+		t.Errorf("got %s, cause must not be marshaled", string(bts))
+	}
+}
+
+func Test_Error_MarshalJSON(t *testing.T) {
+	err := jrpc.ServerError(-32050, `quota "daily" exceeded`)
+	err.Data = map[string]int{"limit": 10}
+
+	bts, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+
+	want := `{"code":-32050,"message":"quota \"daily\" exceeded","data":{"limit":10}}`
+	if string(bts) != want {
+		t.Errorf("got %s, want %s", string(bts), want)
+	}
+
+	var decoded jrpc.Error
+	if err := json.Unmarshal(bts, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.Code != err.Code || decoded.Message != err.Message {
+		t.Errorf("got %+v, want %+v", decoded, *err)
+	}
+}
+
+func Test_ServerError_Range(t *testing.T) {
+	err := jrpc.ServerError(-32100, "out of range")
+
+	if !errors.Is(err, jrpc.InternalError()) || err.Message != "Internal error" {
+		t.Errorf("got %+v, want internal error", err)
+	}
+
+	if cause := errors.Unwrap(err); cause == nil || !strings.Contains(cause.Error(), "-32100") {
+		t.Errorf("got cause %v, want out of range cause", cause)
+	}
+}
+
+type quotaData struct {
+	Limit int `json:"limit"`
+}
+
+func Test_ErrorData(t *testing.T) {
+	err := fmt.Errorf("call: %w", jrpc.NewError(-32050, "Quota exceeded", quotaData{Limit: 10}))
+
+	data, dataErr := jrpc.ErrorData[quotaData](err)
+	if dataErr != nil {
+		t.Fatal(dataErr)
+	}
+
+	if data.Limit != 10 {
+		t.Errorf("got %d, want 10", data.Limit)
+	}
+
+	converted, dataErr := jrpc.ErrorData[map[string]int](err)
+	if dataErr != nil {
+		t.Fatal(dataErr)
+	}
+
+	if converted["limit"] != 10 {
+		t.Errorf("got %v, want limit 10", converted)
+	}
+
+	if _, dataErr = jrpc.ErrorData[quotaData](errors.New("plain")); !errors.Is(dataErr, jrpc.ErrNoErrorData) {
+		t.Errorf("got %v, want %v", dataErr, jrpc.ErrNoErrorData)
+	}
+}
@@ -0,0 +1,189 @@
+package jrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const defaultBatchParallelism = 64
+
+type OverloadPolicy int
+
+const (
+	// OverloadQueue makes calls wait for a free slot of the executor.
+	OverloadQueue OverloadPolicy = iota
+	// OverloadReject makes calls fail with Server busy error if there is no free slot of the executor.
+	OverloadReject
+)
+
+var errServerBusy = errors.New("server busy")
+
+// executor bounds the number of handlers running concurrently across all calls of the router.
+type executor struct {
+	sem    chan struct{}
+	policy OverloadPolicy
+}
+
+// WithMaxConcurrency limits the number of handlers running concurrently across all calls of the router.
+func WithMaxConcurrency(n int) RouterOption {
+	return func(router *engine) {
+		if n <= 0 {
+			router.executor.sem = nil
+
+			return
+		}
+
+		router.executor.sem = make(chan struct{}, n)
+	}
+}
+
+// WithBatchParallelism limits the number of members of one batch handled concurrently,
+// including DontRender members running in background.
+func WithBatchParallelism(n int) RouterOption {
+	return func(router *engine) {
+		router.batchParallelism = n
+	}
+}
+
+// WithOrderedBatch makes batch responses rendered in the order of requests, members are still handled concurrently.
+func WithOrderedBatch() RouterOption {
+	return func(router *engine) {
+		router.orderedBatch = true
+	}
+}
+
+// WithSequentialBatch makes batch members handled one by one in the order of requests,
+// for batches whose members depend on side effects of earlier ones. Responses are rendered in the order of requests.
+// DontRender members are handled inline too, the next member starts after they return.
+func WithSequentialBatch() RouterOption {
+	return func(router *engine) {
+		router.sequentialBatch = true
+	}
+}
+
+func WithOverloadPolicy(policy OverloadPolicy) RouterOption {
+	return func(router *engine) {
+		router.executor.policy = policy
+	}
+}
+
+func (e *executor) acquire(ctx context.Context) error {
+	if e.sem == nil {
+		return nil
+	}
+
+	if e.policy == OverloadReject {
+		select {
+		case e.sem <- struct{}{}:
+			return nil
+		default:
+			return errServerBusy
+		}
+	}
+
+	select {
+	case e.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *executor) release() {
+	if e.sem != nil {
+		<-e.sem
+	}
+}
+
+func (router *engine) batchWorkers(batchLen int) int {
+	if router.sequentialBatch {
+		return 1
+	}
+
+	if router.batchParallelism <= 0 || batchLen < router.batchParallelism {
+		return batchLen
+	}
+
+	return router.batchParallelism
+}
+
+func (router *engine) execute(ctx context.Context, h *handler) (any, error) {
+	if timeout := router.handlerTimeout(h); timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, contextError(err)
+	}
+
+	if err := router.executor.acquire(ctx); err != nil {
+		if errors.Is(err, errServerBusy) {
+			return nil, ServerBusyError()
+		}
+
+		return nil, contextError(err)
+	}
+
+	if _, ok := ctx.Deadline(); ok {
+		return router.callWithDeadline(ctx, h, router.executor.release)
+	}
+
+	defer router.executor.release()
+
+	return router.call(ctx, h)
+}
+
+// executeDetached runs the handler in background. If slots is not nil, the handler takes a slot until it returns,
+// and the call waits for a free one, so background members of a batch are bounded by the batch parallelism.
+func (router *engine) executeDetached(ctx context.Context, h *handler, slots chan struct{}) {
+	detachedCtx, done, err := router.lifecycle.start(ctx)
+	if err != nil {
+		router.logger.Error(fmt.Sprintf("notification is dropped: %v", err.Error()))
+
+		return
+	}
+
+	if slots != nil {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			done()
+			router.logger.Error(fmt.Sprintf("notification is dropped: %v", ctx.Err().Error()))
+
+			return
+		}
+	}
+
+	releaseSlot := func() {
+		if slots != nil {
+			<-slots
+		}
+	}
+
+	if err := router.executor.acquire(ctx); err != nil {
+		releaseSlot()
+		done()
+		router.logger.Error(fmt.Sprintf("notification is dropped: %v", err.Error()))
+
+		return
+	}
+
+	go func() {
+		defer done()
+		defer releaseSlot()
+		defer router.executor.release()
+
+		if timeout := router.handlerTimeout(h); timeout > 0 {
+			var cancel context.CancelFunc
+
+			detachedCtx, cancel = context.WithTimeout(detachedCtx, timeout)
+			defer cancel()
+		}
+
+		_, _ = router.call(detachedCtx, h)
+	}()
+}
@@ -1,19 +1,34 @@
 package jrpc
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"time"
 )
 
 type HTTPOption func(*HTTPRouter)
 
+// HTTPRouter serves JSON-RPC over HTTP. It implements http.Handler, so it can be mounted into existing server
+// instead of Run.
 type HTTPRouter struct {
 	srv *http.Server
 
-	endPoint string
-	logger   *slog.Logger
+	certFile string
+	keyFile  string
+
+	endPoint  string
+	routes    map[string]*Router
+	logger    *slog.Logger
+	encodings map[string]WireEncoding
+
+	handler  *HTTPHandler
+	handlers map[string]*HTTPHandler
 
 	*Router
 }
@@ -39,9 +54,23 @@ func NewHTTPRouter(addr string, opts ...HTTPOption) *HTTPRouter {
 		router.endPoint = "/"
 	}
 
+	router.handler = router.newHandler(router.Router)
+
+	router.handlers = make(map[string]*HTTPHandler, len(router.routes))
+	for path, route := range router.routes {
+		router.handlers[path] = router.newHandler(route)
+	}
+
 	return router
 }
 
+func (httpRouter *HTTPRouter) newHandler(router *Router) *HTTPHandler {
+	handler := NewHTTPHandler(router)
+	handler.encodings = httpRouter.encodings
+
+	return handler
+}
+
 func WithLogger(logger *slog.Logger) HTTPOption {
 	return func(router *HTTPRouter) {
 		router.logger = logger
@@ -54,47 +83,250 @@ func WithEndPoint(endPoint string) HTTPOption {
 	}
 }
 
-func (httpRouter *HTTPRouter) Run() error {
-	mux := http.NewServeMux()
+// WithRoute serves another router on the path, so one server can serve several routers, e.g. "/v1/rpc" and "/admin/rpc".
+func WithRoute(path string, router *Router) HTTPOption {
+	return func(httpRouter *HTTPRouter) {
+		if httpRouter.routes == nil {
+			httpRouter.routes = make(map[string]*Router)
+		}
+
+		httpRouter.routes[path] = router
+	}
+}
+
+// WithTLS makes Run serve HTTPS with the certificate and the key files.
+func WithTLS(certFile, keyFile string) HTTPOption {
+	return func(router *HTTPRouter) {
+		router.certFile = certFile
+		router.keyFile = keyFile
+	}
+}
+
+// WithTLSConfig sets TLS config of the server. Run serves HTTPS if the config contains certificates.
+func WithTLSConfig(config *tls.Config) HTTPOption {
+	return func(router *HTTPRouter) {
+		router.srv.TLSConfig = config
+	}
+}
+
+// WithTimeouts sets read, write and idle timeouts of the server, zero value means no timeout.
+func WithTimeouts(read, write, idle time.Duration) HTTPOption {
+	return func(router *HTTPRouter) {
+		router.srv.ReadTimeout = read
+		router.srv.WriteTimeout = write
+		router.srv.IdleTimeout = idle
+	}
+}
+
+// WithHTTPServer allows to change any settings of the underlying http.Server.
+func WithHTTPServer(configure func(srv *http.Server)) HTTPOption {
+	return func(router *HTTPRouter) {
+		configure(router.srv)
+	}
+}
 
-	mux.HandleFunc(httpRouter.endPoint, func(w http.ResponseWriter, req *http.Request) {
-		if req.RequestURI != httpRouter.endPoint {
-			http.Error(w, "404 page not found", http.StatusNotFound)
+// WithWireEncodings enables binary encodings of requests, chosen by Content-Type header of the request.
+// Requests with other Content-Type are handled as JSON.
+func WithWireEncodings(encodings ...WireEncoding) HTTPOption {
+	return func(router *HTTPRouter) {
+		if router.encodings == nil {
+			router.encodings = make(map[string]WireEncoding)
+		}
 
-			return
+		for _, encoding := range encodings {
+			router.encodings[encoding.ContentType()] = encoding
 		}
+	}
+}
 
-		httpRouter.Handle(w, req)
-	})
+// Run listens on the address of the router and serves it, it serves HTTPS if TLS is configured.
+func (httpRouter *HTTPRouter) Run() error {
+	if httpRouter.srv.Handler == nil {
+		httpRouter.srv.Handler = httpRouter
+	}
 
-	httpRouter.srv.Handler = mux
+	if httpRouter.certFile != "" || httpRouter.keyFile != "" || httpRouter.hasTLSCertificates() {
+		return httpRouter.srv.ListenAndServeTLS(httpRouter.certFile, httpRouter.keyFile)
+	}
 
 	return httpRouter.srv.ListenAndServe()
 }
 
+func (httpRouter *HTTPRouter) hasTLSCertificates() bool {
+	config := httpRouter.srv.TLSConfig
+
+	return config != nil && (len(config.Certificates) != 0 || config.GetCertificate != nil || config.GetConfigForClient != nil)
+}
+
+// Shutdown stops accepting requests and waits for in-flight requests and background handlers
+// (DontRender handlers) of all routes. If ctx is done before, it cancels context
+// of background handlers and returns ctx error. Notifications run inline, so they are waited for as requests.
+func (httpRouter *HTTPRouter) Shutdown(ctx context.Context) error {
+	errs := []error{httpRouter.srv.Shutdown(ctx), httpRouter.Router.Shutdown(ctx)}
+
+	for _, router := range httpRouter.routes {
+		errs = append(errs, router.Shutdown(ctx))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close immediately closes the server and cancels context of background handlers of all routes.
 func (httpRouter *HTTPRouter) Close() error {
+	httpRouter.Router.engine.lifecycle.close()
+
+	for _, router := range httpRouter.routes {
+		router.engine.lifecycle.close()
+	}
+
 	return httpRouter.srv.Close()
 }
 
+// ServeHTTP routes the request by URL path to the router of the end point or of the route added by WithRoute.
+func (httpRouter *HTTPRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == httpRouter.endPoint {
+		httpRouter.handler.ServeHTTP(w, r)
+
+		return
+	}
+
+	if handler, ok := httpRouter.handlers[r.URL.Path]; ok {
+		handler.ServeHTTP(w, r)
+
+		return
+	}
+
+	http.NotFound(w, r)
+}
+
+// Handle handles the request by the router of the end point regardless of URL path.
 func (httpRouter *HTTPRouter) Handle(w http.ResponseWriter, r *http.Request) {
+	httpRouter.handler.ServeHTTP(w, r)
+}
+
+type HTTPHandlerOption func(*HTTPHandler)
+
+// WithHandlerWireEncodings enables binary encodings of requests of the handler, as WithWireEncodings of HTTPRouter.
+func WithHandlerWireEncodings(encodings ...WireEncoding) HTTPHandlerOption {
+	return func(handler *HTTPHandler) {
+		if handler.encodings == nil {
+			handler.encodings = make(map[string]WireEncoding)
+		}
+
+		for _, encoding := range encodings {
+			handler.encodings[encoding.ContentType()] = encoding
+		}
+	}
+}
+
+// HTTPHandler serves the router over HTTP regardless of URL path, so it can be mounted on any path of existing server.
+type HTTPHandler struct {
+	router    *Router
+	encodings map[string]WireEncoding
+}
+
+func NewHTTPHandler(router *Router, opts ...HTTPHandlerOption) *HTTPHandler {
+	handler := &HTTPHandler{
+		router: router,
+	}
+
+	for _, opt := range opts {
+		opt(handler)
+	}
+
+	return handler
+}
+
+func (handler *HTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if encoding, ok := handler.encodings[mediaType(r.Header.Get("Content-Type"))]; ok {
+		handler.handleEncoded(w, r, encoding)
+
+		return
+	}
+
+	router := handler.router
+
 	w.Header().Set("Content-Type", "application/json")
 
-	bts, err := io.ReadAll(r.Body)
+	bts, err := readBody(w, r, router)
 	if err != nil {
-		_, err = w.Write(errorParsingJSONString)
-		if err != nil {
-			httpRouter.logger.Error(fmt.Sprintf("error during write into ResponseWriter: %v", err.Error()))
+		res, status := readBodyErrorResponse(router, err)
+
+		w.WriteHeader(status)
 
-			return
+		_, err = w.Write(res)
+		if err != nil {
+			router.engine.logger.Error(fmt.Sprintf("error during write into ResponseWriter: %v", err.Error()))
 		}
+
+		return
 	}
 
-	res := httpRouter.Router.engine.handle(r.Context(), bts)
+	err = router.engine.handleTo(r.Context(), w, bts)
+	if err != nil {
+		router.engine.logger.Error(fmt.Sprintf("error during write into ResponseWriter: %v", err.Error()))
 
-	_, err = w.Write(res)
+		return
+	}
+}
+
+func (handler *HTTPHandler) handleEncoded(w http.ResponseWriter, r *http.Request, encoding WireEncoding) {
+	router := handler.router
+
+	w.Header().Set("Content-Type", encoding.ContentType())
+
+	var (
+		res    []byte
+		status = http.StatusOK
+	)
+
+	bts, err := readBody(w, r, router)
 	if err != nil {
-		httpRouter.logger.Error(fmt.Sprintf("error during write into ResponseWriter: %v", err.Error()))
+		res, status = readBodyErrorResponse(router, err)
+	} else if bts, err = encoding.ToJSON(bts); err != nil {
+		res = errorParsingJSONString
+	} else {
+		res = router.engine.handleTranslated(r.Context(), bts)
+	}
 
+	if res == nil {
 		return
 	}
+
+	res, err = encoding.FromJSON(res)
+	if err != nil {
+		router.engine.logger.Error(fmt.Sprintf("error during encoding response: %v", err.Error()))
+		http.Error(w, "500 internal server error", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(status)
+
+	_, err = w.Write(res)
+	if err != nil {
+		router.engine.logger.Error(fmt.Sprintf("error during write into ResponseWriter: %v", err.Error()))
+	}
+}
+
+func readBody(w http.ResponseWriter, r *http.Request, router *Router) ([]byte, error) {
+	if limit := router.engine.limits.maxRequestSize; limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+	}
+
+	return io.ReadAll(r.Body)
+}
+
+func readBodyErrorResponse(router *Router, err error) ([]byte, int) {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return errorParsingJSONString, http.StatusOK
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	router.engine.renderError(buf, requestTooLargeError(maxBytesErr.Limit))
+
+	return bytes.Clone(buf.Bytes()), http.StatusRequestEntityTooLarge
 }
@@ -2,9 +2,15 @@ package jrpc_test
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io"
+	"net"
+	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ananaslegend/jrpc"
 )
@@ -160,3 +166,179 @@ func Test_HTTP_JSON_RPC_2_0_Specification_Examples(t *testing.T) {
 		})
 	}
 }
+
+func Test_HTTP_ServeHTTP_Routes(t *testing.T) {
+	admin := jrpc.NewRouter()
+	admin.Method("ping", func(ctx context.Context) (any, error) {
+		return "admin pong", nil
+	})
+
+	router := jrpc.NewHTTPRouter(":8080", jrpc.WithEndPoint("/v1/rpc"), jrpc.WithRoute("/admin/rpc", admin))
+	router.Method("ping", func(ctx context.Context) (any, error) {
+		return "pong", nil
+	})
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		result string
+	}{
+		{
+			name:   "end point",
+			path:   "/v1/rpc",
+			status: http.StatusOK,
+			result: `{"jsonrpc": "2.0", "result": "pong", "id": 1}`,
+		},
+		{
+			name:   "end point with query string",
+			path:   "/v1/rpc?trace=1",
+			status: http.StatusOK,
+			result: `{"jsonrpc": "2.0", "result": "pong", "id": 1}`,
+		},
+		{
+			name:   "route",
+			path:   "/admin/rpc",
+			status: http.StatusOK,
+			result: `{"jsonrpc": "2.0", "result": "admin pong", "id": 1}`,
+		},
+		{
+			name:   "unknown path",
+			path:   "/v2/rpc",
+			status: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(srv.URL+tt.path, "application/json", strings.NewReader(`{"jsonrpc": "2.0", "method": "ping", "id": 1}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Fatalf("got %d, want %d", resp.StatusCode, tt.status)
+			}
+
+			if tt.result == "" {
+				return
+			}
+
+			bts, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			equals, err := resultsEquals(string(bts), tt.result)
+			if err != nil {
+				t.Errorf("error comparing results: %s", err.Error())
+			}
+
+			if !equals {
+				t.Errorf("got %s, want %s", string(bts), tt.result)
+			}
+		})
+	}
+}
+
+func Test_HTTPHandler_Mount(t *testing.T) {
+	router := jrpc.NewRouter()
+	router.Method("ping", func(ctx context.Context) (any, error) {
+		return "pong", nil
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/rpc", jrpc.NewHTTPHandler(router))
+	mux.Handle("/api/", jrpc.NewHTTPHandler(router))
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for _, path := range []string{"/rpc", "/api/v1/rpc"} {
+		t.Run(path, func(t *testing.T) {
+			resp, err := http.Post(srv.URL+path, "application/json", strings.NewReader(`{"jsonrpc": "2.0", "method": "ping", "id": 1}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("got %d, want %d", resp.StatusCode, http.StatusOK)
+			}
+
+			bts, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := `{"jsonrpc": "2.0", "result": "pong", "id": 1}`
+
+			equals, err := resultsEquals(string(bts), want)
+			if err != nil {
+				t.Errorf("error comparing results: %s", err.Error())
+			}
+
+			if !equals {
+				t.Errorf("got %s, want %s", string(bts), want)
+			}
+		})
+	}
+}
+
+func Test_HTTP_Run_Shutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := listener.Addr().String()
+	listener.Close()
+
+	router := jrpc.NewHTTPRouter(addr, jrpc.WithTimeouts(time.Second, time.Second, time.Second), jrpc.WithHTTPServer(func(srv *http.Server) {
+		srv.MaxHeaderBytes = 1 << 10
+	}))
+	router.Method("ping", func(ctx context.Context) (any, error) {
+		return "pong", nil
+	})
+
+	runErr := make(chan error, 1)
+
+	go func() {
+		runErr <- router.Run()
+	}()
+
+	var resp *http.Response
+
+	for i := 0; i < 100; i++ {
+		resp, err = http.Post("http://"+addr+"/", "application/json", strings.NewReader(`{"jsonrpc": "2.0", "method": "ping", "id": 1}`))
+		if err == nil {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got %d, want 200", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := router.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-runErr; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("got %v, want %v", err, http.ErrServerClosed)
+	}
+}
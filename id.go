@@ -2,8 +2,9 @@ package jrpc
 
 import (
 	"context"
-	"fmt"
+	"strconv"
 
+	"github.com/goccy/go-json"
 	"github.com/valyala/fastjson"
 )
 
@@ -17,6 +18,8 @@ func setRequestID(ctx context.Context, id *requestID) context.Context {
 	return context.WithValue(ctx, idKey{}, id)
 }
 
+// RequestID returns request id as raw JSON, exactly as the client sent it.
+// It returns NullRequestID if request id is not set.
 func RequestID(ctx context.Context) string {
 	id, _ := ctx.Value(idKey{}).(*requestID)
 	if id == nil {
@@ -26,56 +29,62 @@ func RequestID(ctx context.Context) string {
 	return id.String()
 }
 
-type requestID struct {
-	notNull    bool
-	renderNull bool
+// RequestIDString returns request id if it is a JSON string.
+func RequestIDString(ctx context.Context) (string, bool) {
+	id, _ := ctx.Value(idKey{}).(*requestID)
+	if id == nil || !id.notNull || len(id.raw) == 0 || id.raw[0] != '"' {
+		return "", false
+	}
+
+	var s string
+	if err := json.Unmarshal(id.raw, &s); err != nil {
+		return "", false
+	}
 
-	stringID *string
-	intID    *int
-	floatID  *float64
+	return s, true
 }
 
-func (i *requestID) String() string {
-	if !i.notNull {
-		return NullRequestID
+// RequestIDInt returns request id if it is a JSON number with integer value that fits into int64.
+func RequestIDInt(ctx context.Context) (int64, bool) {
+	id, _ := ctx.Value(idKey{}).(*requestID)
+	if id == nil || !id.notNull {
+		return 0, false
 	}
 
-	if i.stringID != nil {
-		return fmt.Sprintf(`"%s"`, *i.stringID)
+	intID, err := strconv.ParseInt(string(id.raw), 10, 64)
+	if err == nil {
+		return intID, true
 	}
 
-	if i.floatID != nil {
-		return fmt.Sprintf(`%v`, *i.floatID)
+	floatID, err := strconv.ParseFloat(string(id.raw), 64)
+	if err != nil || floatID != float64(int64(floatID)) {
+		return 0, false
 	}
 
-	return fmt.Sprintf(`%v`, *i.intID)
+	return int64(floatID), true
 }
 
-func getRequestID(v *fastjson.Value) *requestID {
-	if !v.Exists("id") {
-		return &requestID{}
-	}
-
-	idValue := v.Get("id")
-
-	intID, err := idValue.Int()
-	if err != nil {
-		floatID, err := idValue.Float64()
-		if err != nil {
-			bytesID, err := idValue.StringBytes()
-			if err != nil {
-				nullReqID := NullRequestID
+type requestID struct {
+	notNull    bool
+	renderNull bool
 
-				return &requestID{stringID: &nullReqID, notNull: true}
-			}
+	raw []byte
+}
 
-			stringID := string(bytesID)
+func (i *requestID) String() string {
+	if !i.notNull {
+		return NullRequestID
+	}
 
-			return &requestID{stringID: &stringID, notNull: true}
-		}
+	return string(i.raw)
+}
 
-		return &requestID{floatID: &floatID, notNull: true}
+func getRequestID(v *fastjson.Value) *requestID {
+	idValue := v.Get("id")
+	if idValue == nil {
+		return &requestID{}
 	}
 
-	return &requestID{intID: &intID, notNull: true}
+	// MarshalTo must be called before any typed access to the value, fastjson keeps raw bytes of strings until it.
+	return &requestID{raw: idValue.MarshalTo(nil), notNull: true}
 }
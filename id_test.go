@@ -0,0 +1,65 @@
+package jrpc_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+func Test_RequestID_RawEcho(t *testing.T) {
+	ids := []string{`1`, `"1"`, `"a\"b"`, `"é"`, `"\u00e9"`, `1e21`, `1.0`, `-0`, `18446744073709551616`, `null`}
+
+	router := jrpc.NewRouter()
+	router.Method("ping", func(ctx context.Context) (any, error) {
+		return "pong", nil
+	})
+
+	for _, id := range ids {
+		t.Run(id, func(t *testing.T) {
+			result := router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "ping", "id": `+id+`}`))
+
+			if !strings.HasSuffix(string(result), `"id":`+id+`}`) {
+				t.Errorf("got %s, want id %s", string(result), id)
+			}
+		})
+	}
+}
+
+func Test_RequestID_TypedAccessors(t *testing.T) {
+	tests := []struct {
+		id       string
+		stringID string
+		isString bool
+		intID    int64
+		isInt    bool
+	}{
+		{id: `"a\"b"`, stringID: `a"b`, isString: true},
+		{id: `42`, intID: 42, isInt: true},
+		{id: `1.0`, intID: 1, isInt: true},
+		{id: `1.5`},
+		{id: `18446744073709551616`},
+		{id: `null`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			router := jrpc.NewRouter()
+			router.Method("ping", func(ctx context.Context) (any, error) {
+				stringID, isString := jrpc.RequestIDString(ctx)
+				if stringID != tt.stringID || isString != tt.isString {
+					t.Errorf("got (%q, %v), want (%q, %v)", stringID, isString, tt.stringID, tt.isString)
+				}
+
+				intID, isInt := jrpc.RequestIDInt(ctx)
+				if intID != tt.intID || isInt != tt.isInt {
+					t.Errorf("got (%d, %v), want (%d, %v)", intID, isInt, tt.intID, tt.isInt)
+				}
+
+				return nil, nil
+			})
+
+			router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "ping", "id": `+tt.id+`}`))
+		})
+	}
+}
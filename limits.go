@@ -0,0 +1,120 @@
+package jrpc
+
+import "errors"
+
+var ErrMessageTooLarge = errors.New("jrpc: message too large")
+
+type limits struct {
+	maxRequestSize int64
+	maxBatchLength int
+	maxDepth       int
+	maxParamsSize  int
+}
+
+// WithMaxRequestSize limits the size of request in bytes. HTTP Router responds with 413 status on exceeding it,
+// stream transports skip too large messages without reading them into memory. For binary encodings the size
+// of the encoded message is limited, not the size of its JSON translation.
+func WithMaxRequestSize(n int64) RouterOption {
+	return func(router *engine) {
+		router.limits.maxRequestSize = n
+	}
+}
+
+func WithMaxBatchLength(n int) RouterOption {
+	return func(router *engine) {
+		router.limits.maxBatchLength = n
+	}
+}
+
+// WithMaxDepth limits nesting depth of objects and arrays in the request.
+func WithMaxDepth(n int) RouterOption {
+	return func(router *engine) {
+		router.limits.maxDepth = n
+	}
+}
+
+// WithMaxParamsSize limits the size of params of a single call in bytes.
+func WithMaxParamsSize(n int) RouterOption {
+	return func(router *engine) {
+		router.limits.maxParamsSize = n
+	}
+}
+
+func requestTooLargeError(limit int64) *Error {
+	err := InvalidRequestError("Request too large")
+	err.Data = map[string]any{"limit": limit}
+
+	return err
+}
+
+func batchTooLargeError(limit int) *Error {
+	err := InvalidRequestError("Batch too large")
+	err.Data = map[string]any{"limit": limit}
+
+	return err
+}
+
+func requestTooDeepError(limit int) *Error {
+	err := InvalidRequestError("Request nesting too deep")
+	err.Data = map[string]any{"limit": limit}
+
+	return err
+}
+
+func paramsTooLargeError(limit int) *Error {
+	err := InvalidParamsError("Params too large")
+	err.Data = map[string]any{"limit": limit}
+
+	return err
+}
+
+// checkRequest checks the limits that must be checked before parsing the request.
+// The size isn't checked for requests translated from binary encodings, transports check their wire bytes.
+func (l limits) checkRequest(bts []byte, checkSize bool) *Error {
+	if checkSize && l.maxRequestSize > 0 && int64(len(bts)) > l.maxRequestSize {
+		return requestTooLargeError(l.maxRequestSize)
+	}
+
+	if l.maxDepth > 0 && jsonDepthExceeds(bts, l.maxDepth) {
+		return requestTooDeepError(l.maxDepth)
+	}
+
+	return nil
+}
+
+// jsonDepthExceeds reports whether nesting depth of objects and arrays in JSON exceeds maxDepth.
+func jsonDepthExceeds(bts []byte, maxDepth int) bool {
+	var (
+		depth    int
+		inString bool
+	)
+
+	for i := 0; i < len(bts); i++ {
+		c := bts[i]
+
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return true
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,237 @@
+package jrpc_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+func Test_Limits(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     jrpc.RouterOption
+		request []byte
+		result  []byte
+	}{
+		{
+			name:    "max request size",
+			opt:     jrpc.WithMaxRequestSize(32),
+			request: []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`),
+			result:  []byte(`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Request too large", "data": {"limit": 32}}, "id": null}`),
+		},
+		{
+			name: "max batch length",
+			opt:  jrpc.WithMaxBatchLength(1),
+			request: []byte(`[
+				{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1},
+				{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 2}
+			]`),
+			result: []byte(`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Batch too large", "data": {"limit": 1}}, "id": null}`),
+		},
+		{
+			name:    "max depth",
+			opt:     jrpc.WithMaxDepth(3),
+			request: []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [[["]]]]"]]], "id": 1}`),
+			result:  []byte(`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Request nesting too deep", "data": {"limit": 3}}, "id": null}`),
+		},
+		{
+			name:    "max depth is not exceeded",
+			opt:     jrpc.WithMaxDepth(2),
+			request: []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": "[[["}`),
+			result:  []byte(`{"jsonrpc": "2.0", "result": 19, "id": "[[["}`),
+		},
+		{
+			name:    "max params size",
+			opt:     jrpc.WithMaxParamsSize(4),
+			request: []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`),
+			result:  []byte(`{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Params too large", "data": {"limit": 4}}, "id": 1}`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := jrpc.NewRouter()
+			router.Configure(tt.opt)
+
+			router.Method(subtractHandler.method, subtractHandler.handlerFunc)
+
+			result := router.Handle(context.Background(), tt.request)
+
+			equals, err := resultsEquals(string(result), string(tt.result))
+			if err != nil {
+				t.Errorf("error comparing results: %s", err.Error())
+			}
+
+			if !equals {
+				t.Errorf("got %s, want %s", string(result), string(tt.result))
+			}
+		})
+	}
+}
+
+func Test_HTTP_MaxRequestSize(t *testing.T) {
+	router := jrpc.NewHTTPRouter(":8080")
+	router.Configure(jrpc.WithMaxRequestSize(32))
+
+	router.Method(subtractHandler.method, subtractHandler.handlerFunc)
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`))
+	w := httptest.NewRecorder()
+
+	router.Handle(w, r)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 413 {
+		t.Errorf("got %d, want 413", resp.StatusCode)
+	}
+
+	bts, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Request too large", "data": {"limit": 32}}, "id": null}`
+
+	equals, err := resultsEquals(string(bts), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(bts), want)
+	}
+}
+
+func Test_Stream_MaxRequestSize(t *testing.T) {
+	for _, framing := range []jrpc.Framing{jrpc.ContentLengthFraming, jrpc.NewlineFraming, jrpc.LengthPrefixFraming} {
+		router := jrpc.NewRouter()
+		router.Configure(jrpc.WithMaxRequestSize(80))
+
+		router.Method(subtractHandler.method, subtractHandler.handlerFunc)
+
+		in, out := &bytes.Buffer{}, &bytes.Buffer{}
+
+		requests := []string{
+			`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1, "padding": "too large message"}`,
+			`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 2}`,
+		}
+
+		for _, req := range requests {
+			if err := framing.WriteMessage(in, []byte(req)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := jrpc.NewStreamTransport(router, in, out, jrpc.WithFraming(framing)).Serve(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		reader := bufio.NewReader(out)
+
+		results := []string{
+			`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Request too large", "data": {"limit": 80}}, "id": null}`,
+			`{"jsonrpc": "2.0", "result": 19, "id": 2}`,
+		}
+
+		for _, want := range results {
+			msg, err := framing.ReadMessage(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			equals, err := resultsEquals(string(msg), want)
+			if err != nil {
+				t.Errorf("error comparing results: %s", err.Error())
+			}
+
+			if !equals {
+				t.Errorf("got %s, want %s", string(msg), want)
+			}
+		}
+	}
+}
+
+func Test_Stream_HugeFrameLength(t *testing.T) {
+	tests := []struct {
+		name    string
+		framing jrpc.Framing
+		input   []byte
+	}{
+		{
+			name:    "content length",
+			framing: jrpc.ContentLengthFraming,
+			input:   []byte("Content-Length: 4611686018427387904\r\n\r\n{}"),
+		},
+		{
+			name:    "length prefix",
+			framing: jrpc.LengthPrefixFraming,
+			input:   []byte("\xff\xff\xff\xff{}"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := jrpc.NewRouter()
+
+			out := &bytes.Buffer{}
+
+			err := jrpc.NewStreamTransport(router, bytes.NewReader(tt.input), out, jrpc.WithFraming(tt.framing)).Serve(context.Background())
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("got %v, want %v", err, io.ErrUnexpectedEOF)
+			}
+
+			if out.Len() != 0 {
+				t.Errorf("got %s, want no response", out.String())
+			}
+		})
+	}
+}
+
+func Test_Stream_DefaultMaxMessageSize(t *testing.T) {
+	router := jrpc.NewRouter()
+	router.Method(subtractHandler.method, subtractHandler.handlerFunc)
+
+	in, out := &bytes.Buffer{}, &bytes.Buffer{}
+
+	if err := jrpc.LengthPrefixFraming.WriteMessage(in, make([]byte, 32<<20+1)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := jrpc.LengthPrefixFraming.WriteMessage(in, []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := jrpc.NewStreamTransport(router, in, out, jrpc.WithFraming(jrpc.LengthPrefixFraming)).Serve(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(out)
+
+	results := []string{
+		`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Request too large", "data": {"limit": 33554432}}, "id": null}`,
+		`{"jsonrpc": "2.0", "result": 19, "id": 1}`,
+	}
+
+	for _, want := range results {
+		msg, err := jrpc.LengthPrefixFraming.ReadMessage(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		equals, err := resultsEquals(string(msg), want)
+		if err != nil {
+			t.Errorf("error comparing results: %s", err.Error())
+		}
+
+		if !equals {
+			t.Errorf("got %s, want %s", string(msg), want)
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package jrpc
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/valyala/fastjson"
+)
+
+type msgpackEncoding struct{}
+
+func (msgpackEncoding) ContentType() string {
+	return "application/msgpack"
+}
+
+func (msgpackEncoding) FromJSON(msg []byte) ([]byte, error) {
+	v, err := parseJSONForWire(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return appendMsgpack(nil, v)
+}
+
+func (msgpackEncoding) ToJSON(msg []byte) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	d := &msgpackDecoder{data: msg}
+	if err := d.decode(buf, 0); err != nil {
+		return nil, fmt.Errorf("msgpack: %w", err)
+	}
+
+	if d.pos != len(d.data) {
+		return nil, errors.New("msgpack: unexpected data after the value")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func appendMsgpack(dst []byte, v *fastjson.Value) ([]byte, error) {
+	switch v.Type() {
+	case fastjson.TypeNull:
+		return append(dst, 0xc0), nil
+
+	case fastjson.TypeTrue:
+		return append(dst, 0xc3), nil
+
+	case fastjson.TypeFalse:
+		return append(dst, 0xc2), nil
+
+	case fastjson.TypeNumber:
+		u, i, f, kind, err := jsonNumber(v.String())
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case 'u':
+			return appendMsgpackUint(dst, u), nil
+		case 'i':
+			return appendMsgpackInt(dst, i), nil
+		default:
+			dst = append(dst, 0xcb)
+
+			return binary.BigEndian.AppendUint64(dst, math.Float64bits(f)), nil
+		}
+
+	case fastjson.TypeString:
+		return appendMsgpackString(dst, string(v.GetStringBytes())), nil
+
+	case fastjson.TypeArray:
+		arr := v.GetArray()
+
+		dst = appendMsgpackHeader(dst, len(arr), 0x90, 0xdc)
+
+		for _, item := range arr {
+			var err error
+			if dst, err = appendMsgpack(dst, item); err != nil {
+				return nil, err
+			}
+		}
+
+		return dst, nil
+
+	case fastjson.TypeObject:
+		obj := v.GetObject()
+
+		dst = appendMsgpackHeader(dst, obj.Len(), 0x80, 0xde)
+
+		var err error
+
+		obj.Visit(func(key []byte, item *fastjson.Value) {
+			if err != nil {
+				return
+			}
+
+			dst = appendMsgpackString(dst, string(key))
+			dst, err = appendMsgpack(dst, item)
+		})
+
+		return dst, err
+
+	default:
+		return nil, fmt.Errorf("msgpack: unsupported JSON value type %s", v.Type())
+	}
+}
+
+func appendMsgpackUint(dst []byte, u uint64) []byte {
+	switch {
+	case u <= 0x7f:
+		return append(dst, byte(u))
+	case u <= math.MaxUint8:
+		return append(dst, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xcd), uint16(u))
+	case u <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xce), uint32(u))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xcf), u)
+	}
+}
+
+func appendMsgpackInt(dst []byte, i int64) []byte {
+	switch {
+	case i >= 0:
+		return appendMsgpackUint(dst, uint64(i))
+	case i >= -32:
+		return append(dst, byte(i))
+	case i >= math.MinInt8:
+		return append(dst, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(dst, 0xd1), uint16(i))
+	case i >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(dst, 0xd2), uint32(i))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, 0xd3), uint64(i))
+	}
+}
+
+func appendMsgpackString(dst []byte, s string) []byte {
+	switch n := len(s); {
+	case n <= 31:
+		dst = append(dst, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		dst = append(dst, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		dst = binary.BigEndian.AppendUint16(append(dst, 0xda), uint16(n))
+	default:
+		dst = binary.BigEndian.AppendUint32(append(dst, 0xdb), uint32(n))
+	}
+
+	return append(dst, s...)
+}
+
+func appendMsgpackHeader(dst []byte, n int, fix, code16 byte) []byte {
+	switch {
+	case n <= 15:
+		return append(dst, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(dst, code16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(dst, code16+1), uint32(n))
+	}
+}
+
+type msgpackDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *msgpackDecoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.data)-d.pos < n {
+		return nil, errors.New("unexpected end of data")
+	}
+
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+
+	return b, nil
+}
+
+func (d *msgpackDecoder) uint(size int) (uint64, error) {
+	b, err := d.next(size)
+	if err != nil {
+		return 0, err
+	}
+
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+func (d *msgpackDecoder) length(size int) (int, error) {
+	n, err := d.uint(size)
+	if err != nil {
+		return 0, err
+	}
+
+	if n > uint64(len(d.data)-d.pos) {
+		return 0, errors.New("length exceeds data size")
+	}
+
+	return int(n), nil
+}
+
+func (d *msgpackDecoder) decode(buf *bytes.Buffer, depth int) error {
+	if depth > maxWireDepth {
+		return errWireDepth
+	}
+
+	b, err := d.next(1)
+	if err != nil {
+		return err
+	}
+
+	c := b[0]
+
+	switch {
+	case c <= 0x7f:
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), uint64(c), 10))
+
+		return nil
+
+	case c >= 0xe0:
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(int8(c)), 10))
+
+		return nil
+
+	case c&0xe0 == 0xa0:
+		return d.decodeString(buf, int(c&0x1f))
+
+	case c&0xf0 == 0x90:
+		return d.decodeArray(buf, int(c&0x0f), depth)
+
+	case c&0xf0 == 0x80:
+		return d.decodeMap(buf, int(c&0x0f), depth)
+	}
+
+	switch c {
+	case 0xc0:
+		buf.WriteString("null")
+
+	case 0xc2:
+		buf.WriteString("false")
+
+	case 0xc3:
+		buf.WriteString("true")
+
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return err
+		}
+
+		buf.Write(strconv.AppendUint(buf.AvailableBuffer(), u, 10))
+
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+
+		u, err := d.uint(size)
+		if err != nil {
+			return err
+		}
+
+		var i int64
+
+		switch size {
+		case 1:
+			i = int64(int8(u))
+		case 2:
+			i = int64(int16(u))
+		case 4:
+			i = int64(int32(u))
+		default:
+			i = int64(u)
+		}
+
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), i, 10))
+
+	case 0xca:
+		u, err := d.uint(4)
+		if err != nil {
+			return err
+		}
+
+		return writeJSONFloat(buf, float64(math.Float32frombits(uint32(u))))
+
+	case 0xcb:
+		u, err := d.uint(8)
+		if err != nil {
+			return err
+		}
+
+		return writeJSONFloat(buf, math.Float64frombits(u))
+
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.length(1 << (c - 0xd9))
+		if err != nil {
+			return err
+		}
+
+		return d.decodeString(buf, n)
+
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.length(1 << (c - 0xc4))
+		if err != nil {
+			return err
+		}
+
+		bin, err := d.next(n)
+		if err != nil {
+			return err
+		}
+
+		buf.WriteByte('"')
+		buf.WriteString(base64.StdEncoding.EncodeToString(bin))
+		buf.WriteByte('"')
+
+	case 0xdc, 0xdd:
+		n, err := d.length(2 << (c - 0xdc))
+		if err != nil {
+			return err
+		}
+
+		return d.decodeArray(buf, n, depth)
+
+	case 0xde, 0xdf:
+		n, err := d.length(2 << (c - 0xde))
+		if err != nil {
+			return err
+		}
+
+		return d.decodeMap(buf, n, depth)
+
+	default:
+		return fmt.Errorf("unsupported type 0x%x", c)
+	}
+
+	return nil
+}
+
+func (d *msgpackDecoder) decodeString(buf *bytes.Buffer, n int) error {
+	s, err := d.next(n)
+	if err != nil {
+		return err
+	}
+
+	writeString(buf, string(s))
+
+	return nil
+}
+
+func (d *msgpackDecoder) decodeArray(buf *bytes.Buffer, n int, depth int) error {
+	buf.WriteByte('[')
+
+	for i := 0; i < n; i++ {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+
+		if err := d.decode(buf, depth+1); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteByte(']')
+
+	return nil
+}
+
+func (d *msgpackDecoder) decodeMap(buf *bytes.Buffer, n int, depth int) error {
+	buf.WriteByte('{')
+
+	for i := 0; i < n; i++ {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+
+		if err := d.decodeKey(buf, depth); err != nil {
+			return err
+		}
+
+		buf.WriteByte(':')
+
+		if err := d.decode(buf, depth+1); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteByte('}')
+
+	return nil
+}
+
+// decodeKey writes map key as JSON string, numeric keys are converted into strings.
+func (d *msgpackDecoder) decodeKey(buf *bytes.Buffer, depth int) error {
+	if d.pos < len(d.data) {
+		if c := d.data[d.pos]; c&0xe0 == 0xa0 || (c >= 0xd9 && c <= 0xdb) {
+			return d.decode(buf, depth+1)
+		}
+	}
+
+	key := &bytes.Buffer{}
+	if err := d.decode(key, depth+1); err != nil {
+		return err
+	}
+
+	if _, err := strconv.ParseFloat(key.String(), 64); err != nil {
+		return errors.New("map key must be a string or a number")
+	}
+
+	writeString(buf, key.String())
+
+	return nil
+}
@@ -0,0 +1,315 @@
+package jrpc
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+const (
+	OpenRPCVersion = "1.2.6"
+
+	DiscoverMethod = "rpc.discover"
+)
+
+type Schema map[string]any
+
+type OpenRPCInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type OpenRPCDocument struct {
+	OpenRPC string          `json:"openrpc"`
+	Info    OpenRPCInfo     `json:"info"`
+	Methods []OpenRPCMethod `json:"methods"`
+}
+
+type OpenRPCMethod struct {
+	Name           string                     `json:"name"`
+	Summary        string                     `json:"summary,omitempty"`
+	Description    string                     `json:"description,omitempty"`
+	ParamStructure string                     `json:"paramStructure,omitempty"`
+	Params         []OpenRPCContentDescriptor `json:"params"`
+	Result         *OpenRPCContentDescriptor  `json:"result,omitempty"`
+}
+
+type OpenRPCContentDescriptor struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required,omitempty"`
+	Schema   Schema `json:"schema"`
+}
+
+func Summary(summary string) Option {
+	return func(h *handler) {
+		h.summary = summary
+	}
+}
+
+func Description(description string) Option {
+	return func(h *handler) {
+		h.description = description
+	}
+}
+
+// OpenRPC generates OpenRPC document of all registered methods. Params and result schemas are reflected
+// from types of handlers registered with Register.
+func (r *Router) OpenRPC(info OpenRPCInfo) *OpenRPCDocument {
+	names := make([]string, 0, len(r.engine.handlersMap))
+
+	for name := range r.engine.handlersMap {
+		if strings.HasPrefix(name, "rpc.") {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	doc := &OpenRPCDocument{
+		OpenRPC: OpenRPCVersion,
+		Info:    info,
+		Methods: make([]OpenRPCMethod, 0, len(names)),
+	}
+
+	for _, name := range names {
+		doc.Methods = append(doc.Methods, openRPCMethod(name, r.engine.handlersMap[name]))
+	}
+
+	return doc
+}
+
+// RegisterDiscover registers reserved rpc.discover method that returns OpenRPC document of the router.
+// The method is wrapped by middlewares of the router, but it's not prefixed by the group path.
+func (r *Router) RegisterDiscover(info OpenRPCInfo) {
+	r.setHasMethods()
+
+	r.engine.handleMethod(DiscoverMethod, &handler{
+		handlerFunc: r.wrap(func(ctx context.Context) (any, error) {
+			return r.OpenRPC(info), nil
+		}),
+		description: "Returns an OpenRPC schema as a description of this service",
+	})
+}
+
+func openRPCMethod(name string, h *handler) OpenRPCMethod {
+	method := OpenRPCMethod{
+		Name:        name,
+		Summary:     h.summary,
+		Description: h.description,
+		Params:      []OpenRPCContentDescriptor{},
+		Result:      &OpenRPCContentDescriptor{Name: "result", Schema: Schema{}},
+	}
+
+	if h.resultType != nil {
+		method.Result.Schema = reflectSchema(h.resultType, map[reflect.Type]bool{})
+	}
+
+	if h.paramsSchema != nil {
+		method.Params, method.ParamStructure = schemaParams(h.paramsSchema)
+
+		return method
+	}
+
+	if h.paramsType == nil {
+		return method
+	}
+
+	paramsType := h.paramsType
+	for paramsType.Kind() == reflect.Pointer {
+		paramsType = paramsType.Elem()
+	}
+
+	switch {
+	case paramsType.Kind() == reflect.Struct && paramsType != reflect.TypeFor[time.Time]():
+		method.ParamStructure = "by-name"
+
+		schema := reflectSchema(paramsType, map[reflect.Type]bool{})
+
+		properties, _ := schema["properties"].(map[string]Schema)
+		required, _ := schema["required"].([]string)
+
+		for _, field := range sortedKeys(properties) {
+			method.Params = append(method.Params, OpenRPCContentDescriptor{
+				Name:     field,
+				Required: slices.Contains(required, field),
+				Schema:   properties[field],
+			})
+		}
+
+	case paramsType.Kind() == reflect.Array:
+		method.ParamStructure = "by-position"
+
+		itemSchema := reflectSchema(paramsType.Elem(), map[reflect.Type]bool{})
+
+		for i := 0; i < paramsType.Len(); i++ {
+			method.Params = append(method.Params, OpenRPCContentDescriptor{
+				Name:     "param" + strconv.Itoa(i),
+				Required: true,
+				Schema:   itemSchema,
+			})
+		}
+
+	default:
+		method.Params = append(method.Params, OpenRPCContentDescriptor{
+			Name:   "params",
+			Schema: reflectSchema(paramsType, map[reflect.Type]bool{}),
+		})
+	}
+
+	return method
+}
+
+// schemaParams returns content descriptors of params described by the schema, properties of object are described separately.
+func schemaParams(schema Schema) ([]OpenRPCContentDescriptor, string) {
+	properties := schemaProperties(schema["properties"])
+	if !schemaAllowsType(schema, "object") || len(properties) == 0 {
+		return []OpenRPCContentDescriptor{{Name: "params", Schema: schema}}, ""
+	}
+
+	required := schemaStrings(schema["required"])
+	params := make([]OpenRPCContentDescriptor, 0, len(properties))
+
+	for _, field := range sortedKeys(properties) {
+		params = append(params, OpenRPCContentDescriptor{
+			Name:     field,
+			Required: slices.Contains(required, field),
+			Schema:   properties[field],
+		})
+	}
+
+	return params, "by-name"
+}
+
+func reflectSchema(t reflect.Type, seen map[reflect.Type]bool) Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case reflect.TypeFor[time.Time]():
+		return Schema{"type": "string", "format": "date-time"}
+	case reflect.TypeFor[json.RawMessage]():
+		return Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return Schema{"type": "integer"}
+
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+
+	case reflect.String:
+		return Schema{"type": "string"}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+
+		schema := Schema{"type": "array", "items": reflectSchema(t.Elem(), seen)}
+
+		if t.Kind() == reflect.Array {
+			schema["minItems"] = t.Len()
+			schema["maxItems"] = t.Len()
+		}
+
+		return schema
+
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return Schema{"type": "object"}
+		}
+
+		return Schema{"type": "object", "additionalProperties": reflectSchema(t.Elem(), seen)}
+
+	case reflect.Struct:
+		if seen[t] {
+			return Schema{"type": "object"}
+		}
+
+		seen[t] = true
+		defer delete(seen, t)
+
+		properties := map[string]Schema{}
+		required := []string{}
+
+		reflectStructFields(t, seen, properties, &required)
+
+		schema := Schema{"type": "object", "properties": properties}
+
+		if len(required) != 0 {
+			schema["required"] = required
+		}
+
+		return schema
+
+	default:
+		return Schema{}
+	}
+}
+
+func reflectStructFields(t reflect.Type, seen map[reflect.Type]bool, properties map[string]Schema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, tagOpts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			for fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+
+			if fieldType.Kind() == reflect.Struct {
+				reflectStructFields(fieldType, seen, properties, required)
+
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = reflectSchema(field.Type, seen)
+
+		if !strings.Contains(tagOpts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,124 @@
+package jrpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+func Test_OpenRPC_Discover(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	math := router.Group("math")
+
+	jrpc.Register(math, "subtract", func(ctx context.Context, p subtractParams) (int, error) {
+		return p.Minuend - p.Subtrahend, nil
+	}, jrpc.Summary("Subtracts subtrahend from minuend"))
+
+	jrpc.Register(math, "sum", func(ctx context.Context, p [2]float64) (float64, error) {
+		return p[0] + p[1], nil
+	})
+
+	router.Method(getDataHandler.method, getDataHandler.handlerFunc, jrpc.Description("Returns some data"))
+
+	router.RegisterDiscover(jrpc.OpenRPCInfo{Title: "test", Version: "1.0.0"})
+
+	result := router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "rpc.discover", "id": 1}`))
+
+	want := `{"jsonrpc": "2.0", "result": {
+		"openrpc": "1.2.6",
+		"info": {"title": "test", "version": "1.0.0"},
+		"methods": [
+			{
+				"name": "get_data",
+				"description": "Returns some data",
+				"params": [],
+				"result": {"name": "result", "schema": {}}
+			},
+			{
+				"name": "math.subtract",
+				"summary": "Subtracts subtrahend from minuend",
+				"paramStructure": "by-name",
+				"params": [
+					{"name": "minuend", "required": true, "schema": {"type": "integer"}},
+					{"name": "subtrahend", "required": true, "schema": {"type": "integer"}}
+				],
+				"result": {"name": "result", "schema": {"type": "integer"}}
+			},
+			{
+				"name": "math.sum",
+				"paramStructure": "by-position",
+				"params": [
+					{"name": "param0", "required": true, "schema": {"type": "number"}},
+					{"name": "param1", "required": true, "schema": {"type": "number"}}
+				],
+				"result": {"name": "result", "schema": {"type": "number"}}
+			}
+		]
+	}, "id": 1}`
+
+	equals, err := resultsEquals(string(result), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(result), want)
+	}
+}
+
+func Test_OpenRPC_Discover_Middlewares(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	router.Use(func(next jrpc.HandlerFunc) jrpc.HandlerFunc {
+		return func(ctx context.Context) (any, error) {
+			return nil, &jrpc.Error{Code: -32003, Message: "unauthorized"}
+		}
+	})
+
+	router.RegisterDiscover(jrpc.OpenRPCInfo{Title: "test", Version: "1.0.0"})
+
+	want := `{"jsonrpc": "2.0", "error": {"code": -32003, "message": "unauthorized"}, "id": 1}`
+	got := router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "rpc.discover", "id": 1}`))
+
+	equals, err := resultsEquals(string(got), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(got), want)
+	}
+}
+
+func Test_OpenRPC_ParamsSchema(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	router.Method("users.create", func(ctx context.Context) (any, error) {
+		return nil, nil
+	}, jrpc.ParamsSchema(jrpc.Schema{
+		"type":     "object",
+		"required": []string{"name"},
+		"properties": map[string]any{
+			"name": jrpc.Schema{"type": "string"},
+			"age":  jrpc.Schema{"type": "integer"},
+		},
+	}))
+
+	doc := router.OpenRPC(jrpc.OpenRPCInfo{Title: "test", Version: "1.0.0"})
+
+	method := doc.Methods[0]
+
+	if method.ParamStructure != "by-name" || len(method.Params) != 2 {
+		t.Fatalf("got %+v, want by-name params age and name", method)
+	}
+
+	if method.Params[0].Name != "age" || method.Params[0].Required {
+		t.Errorf("got %+v, want optional age", method.Params[0])
+	}
+
+	if method.Params[1].Name != "name" || !method.Params[1].Required {
+		t.Errorf("got %+v, want required name", method.Params[1])
+	}
+}
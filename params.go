@@ -3,7 +3,6 @@ package jrpc
 import (
 	"context"
 
-	"github.com/goccy/go-json"
 	"github.com/valyala/fastjson"
 )
 
@@ -26,7 +25,7 @@ func ParamsTo[T any](ctx context.Context) (*T, error) {
 
 	t := new(T)
 
-	if err := json.Unmarshal(params, t); err != nil {
+	if err := codecFromContext(ctx).Unmarshal(params, t); err != nil {
 		return nil, InvalidParamsError()
 	}
 
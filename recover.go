@@ -0,0 +1,35 @@
+package jrpc
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+func WithDebug() RouterOption {
+	return func(router *engine) {
+		router.debug = true
+	}
+}
+
+func (router *engine) call(ctx context.Context, h *handler) (res any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			stack := string(debug.Stack())
+
+			router.logger.Error(fmt.Sprintf("panic during handling request: %v", p), "stack", stack)
+
+			panicErr := InternalError()
+			if router.debug {
+				panicErr.Data = map[string]any{
+					"panic": fmt.Sprint(p),
+					"stack": stack,
+				}
+			}
+
+			res, err = nil, panicErr
+		}
+	}()
+
+	return h.handlerFunc(ctx)
+}
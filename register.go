@@ -0,0 +1,46 @@
+package jrpc
+
+import (
+	"context"
+	"reflect"
+)
+
+// Register registers a typed handler. Request params are decoded into P before the call,
+// decoding failure is returned as Invalid params error with decoding error in Data.
+// Decoded params are validated by validate tags of P, failures are returned as Invalid params error
+// with the list of FieldError in Data.
+func Register[P, R any](r *Router, method string, fn func(ctx context.Context, p P) (R, error), opts ...Option) {
+	validator := newStructValidator(reflect.TypeFor[P]())
+
+	handlerFunc := func(ctx context.Context) (any, error) {
+		var p P
+
+		if params := Params(ctx); params != nil {
+			if err := codecFromContext(ctx).Unmarshal(params, &p); err != nil {
+				invalidParamsErr := InvalidParamsError()
+				invalidParamsErr.Data = map[string]any{"error": err.Error()}
+
+				return nil, invalidParamsErr
+			}
+		}
+
+		if validator != nil {
+			if fieldErrors := validator.validate(reflect.ValueOf(&p)); len(fieldErrors) != 0 {
+				return nil, invalidParamsFieldsError(fieldErrors)
+			}
+		}
+
+		return fn(ctx, p)
+	}
+
+	opts = append(opts, withTypes(reflect.TypeFor[P](), reflect.TypeFor[R]()))
+
+	r.Method(method, handlerFunc, opts...)
+}
+
+func withTypes(paramsType, resultType reflect.Type) Option {
+	return func(h *handler) {
+		h.paramsType = paramsType
+		h.resultType = resultType
+	}
+}
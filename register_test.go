@@ -0,0 +1,85 @@
+package jrpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goccy/go-json"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+type subtractParams struct {
+	Subtrahend int `json:"subtrahend"`
+	Minuend    int `json:"minuend"`
+}
+
+func Test_Register(t *testing.T) {
+	tests := []struct {
+		name    string
+		request []byte
+		result  []byte
+	}{
+		{
+			name:    "typed params",
+			request: []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": {"subtrahend": 23, "minuend": 42}, "id": 1}`),
+			result:  []byte(`{"jsonrpc": "2.0", "result": 19, "id": 1}`),
+		},
+		{
+			name:    "without params",
+			request: []byte(`{"jsonrpc": "2.0", "method": "subtract", "id": 2}`),
+			result:  []byte(`{"jsonrpc": "2.0", "result": 0, "id": 2}`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := jrpc.NewRouter()
+
+			jrpc.Register(router, "subtract", func(ctx context.Context, p subtractParams) (int, error) {
+				return p.Minuend - p.Subtrahend, nil
+			})
+
+			result := router.Handle(context.Background(), tt.request)
+
+			equals, err := resultsEquals(string(result), string(tt.result))
+			if err != nil {
+				t.Errorf("error comparing results: %s", err.Error())
+			}
+
+			if !equals {
+				t.Errorf("got %s, want %s", string(result), string(tt.result))
+			}
+		})
+	}
+}
+
+func Test_Register_InvalidParams(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	jrpc.Register(router, "subtract", func(ctx context.Context, p subtractParams) (int, error) {
+		return p.Minuend - p.Subtrahend, nil
+	})
+
+	result := router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`))
+
+	var resp struct {
+		Error struct {
+			Code int `json:"code"`
+			Data struct {
+				Error string `json:"error"`
+			} `json:"data"`
+		} `json:"error"`
+	}
+
+	if err := json.Unmarshal(result, &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Error.Code != -32602 {
+		t.Errorf("got code %d, want -32602", resp.Error.Code)
+	}
+
+	if resp.Error.Data.Error == "" {
+		t.Errorf("got empty error data in %s", string(result))
+	}
+}
@@ -0,0 +1,196 @@
+package jrpc
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+const maxPooledBufferSize = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		return &bytes.Buffer{}
+	},
+}
+
+func getBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// renderResponse writes the results, errors of marshaling results are converted by mapErr.
+func renderResponse(buf *bytes.Buffer, codec Codec, results []result, isButch bool, mapErr func(method string, err error) *Error) {
+	if len(results) == 0 {
+		return
+	}
+
+	if isButch {
+		buf.WriteByte('[')
+	}
+
+	for i := range results {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+
+		results[i].writeJSON(buf, codec, mapErr)
+	}
+
+	if isButch {
+		buf.WriteByte(']')
+	}
+}
+
+func (r *result) writeJSON(buf *bytes.Buffer, codec Codec, mapErr func(method string, err error) *Error) {
+	buf.WriteString(`{"jsonrpc":"2.0",`)
+
+	if r.Err == nil {
+		start := buf.Len()
+
+		buf.WriteString(`"result":`)
+
+		if err := writeValue(buf, codec, r.Res); err != nil {
+			buf.Truncate(start)
+
+			r.Err = mapErr(r.method, fmt.Errorf("error during marshaling result: %w", err))
+		}
+	}
+
+	if r.Err != nil {
+		buf.WriteString(`"error":`)
+		r.Err.writeJSON(buf, codec)
+	}
+
+	buf.WriteString(`,"id":`)
+	buf.WriteString(r.Id.String())
+	buf.WriteByte('}')
+}
+
+func (e *Error) writeJSON(buf *bytes.Buffer, codec Codec) {
+	buf.WriteString(`{"code":`)
+	buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(e.Code), 10))
+	buf.WriteString(`,"message":`)
+	writeString(buf, e.Message)
+
+	if e.Data != nil {
+		start := buf.Len()
+
+		buf.WriteString(`,"data":`)
+
+		if err := writeValue(buf, codec, e.Data); err != nil {
+			buf.Truncate(start)
+			buf.WriteString(`,"data":`)
+			writeString(buf, "error during marshaling error data: "+err.Error())
+		}
+	}
+
+	buf.WriteByte('}')
+}
+
+func writeValue(buf *bytes.Buffer, codec Codec, v any) error {
+	if v == nil {
+		buf.WriteString("null")
+
+		return nil
+	}
+
+	bts, err := codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	buf.Write(bts)
+
+	return nil
+}
+
+const hexDigits = "0123456789abcdef"
+
+func writeString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+
+	start := 0
+
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+
+				continue
+			}
+
+			buf.WriteString(s[start:i])
+
+			switch c {
+			case '"', '\\':
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+			case '\n':
+				buf.WriteString(`\n`)
+			case '\r':
+				buf.WriteString(`\r`)
+			case '\t':
+				buf.WriteString(`\t`)
+			default:
+				buf.WriteString(`\u00`)
+				buf.WriteByte(hexDigits[c>>4])
+				buf.WriteByte(hexDigits[c&0xF])
+			}
+
+			i++
+			start = i
+
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+
+		if r == utf8.RuneError && size == 1 {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\ufffd`)
+
+			i += size
+			start = i
+
+			continue
+		}
+
+		if r == '\u2028' || r == '\u2029' {
+			buf.WriteString(s[start:i])
+			buf.WriteString(`\u202`)
+			buf.WriteByte(hexDigits[r&0xF])
+
+			i += size
+			start = i
+
+			continue
+		}
+
+		i += size
+	}
+
+	buf.WriteString(s[start:])
+	buf.WriteByte('"')
+}
+
+func writeResponse(w io.Writer, buf *bytes.Buffer) error {
+	if buf.Len() == 0 {
+		return nil
+	}
+
+	_, err := buf.WriteTo(w)
+
+	return err
+}
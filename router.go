@@ -2,18 +2,28 @@ package jrpc
 
 import (
 	"context"
+	"io"
 	"log/slog"
 )
 
+type HandlerFunc func(ctx context.Context) (any, error)
+
+type Middleware func(next HandlerFunc) HandlerFunc
+
 type Option func(*handler)
 
 func DontRender(h *handler) {
 	h.dontRender = true
 }
 
+type RouterOption func(*engine)
+
 type Router struct {
-	path   string
-	engine *engine
+	path        string
+	middlewares []Middleware
+	parent      *Router
+	hasMethods  bool
+	engine      *engine
 }
 
 func NewRouter(logger ...*slog.Logger) *Router {
@@ -22,27 +32,54 @@ func NewRouter(logger ...*slog.Logger) *Router {
 	}
 }
 
+func (r *Router) Configure(opts ...RouterOption) {
+	for _, opt := range opts {
+		opt(r.engine)
+	}
+}
+
+// Use appends middlewares to the router. Middlewares of the router and of its parent routers are applied
+// in registration order to methods of the router and its groups. Use panics if methods are already registered
+// on the router or its groups, as middlewares are applied at registration and would silently skip them.
+func (r *Router) Use(mw ...Middleware) {
+	if r.hasMethods {
+		panic("jrpc: Use is called after methods are registered, middlewares would not apply to them")
+	}
+
+	r.middlewares = append(r.middlewares, mw...)
+}
+
 func (r *Router) Group(method string) *Router {
 	if r.path == "" {
 		return &Router{
 			path:   method,
+			parent: r,
 			engine: r.engine,
 		}
 	}
 
 	return &Router{
 		path:   r.path + "." + method,
+		parent: r,
 		engine: r.engine,
 	}
 }
 
 func (r *Router) Method(method string, handlerFunc func(ctx context.Context) (any, error), opts ...Option) {
-	h := &handler{handlerFunc: handlerFunc}
+	h := &handler{}
 
 	for _, opt := range opts {
 		opt(h)
 	}
 
+	if h.paramsSchema != nil {
+		handlerFunc = validateSchema(h.paramsSchema, handlerFunc)
+	}
+
+	h.handlerFunc = r.wrap(handlerFunc)
+
+	r.setHasMethods()
+
 	if r.path == "" {
 		r.engine.handleMethod(method, h)
 
@@ -52,6 +89,30 @@ func (r *Router) Method(method string, handlerFunc func(ctx context.Context) (an
 	r.engine.handleMethod(r.path+"."+method, h)
 }
 
+func (r *Router) wrap(handlerFunc HandlerFunc) HandlerFunc {
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		handlerFunc = r.middlewares[i](handlerFunc)
+	}
+
+	if r.parent != nil {
+		return r.parent.wrap(handlerFunc)
+	}
+
+	return handlerFunc
+}
+
+// setHasMethods marks the router and its parents, so their Use can't be called anymore.
+func (r *Router) setHasMethods() {
+	for router := r; router != nil; router = router.parent {
+		router.hasMethods = true
+	}
+}
+
 func (r *Router) Handle(ctx context.Context, jsonRPCRequest []byte) []byte {
 	return r.engine.handle(ctx, jsonRPCRequest)
 }
+
+// HandleTo handles the request and writes the response into w. Nothing is written if there is no response.
+func (r *Router) HandleTo(ctx context.Context, w io.Writer, jsonRPCRequest []byte) error {
+	return r.engine.handleTo(ctx, w, jsonRPCRequest)
+}
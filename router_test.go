@@ -3,9 +3,13 @@ package jrpc_test
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"reflect"
 	"sort"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/goccy/go-json"
 
@@ -263,3 +267,451 @@ func sortJSONData(data interface{}) interface{} {
 		return v
 	}
 }
+
+func Test_Middlewares(t *testing.T) {
+	var calls []string
+
+	mw := func(name string) jrpc.Middleware {
+		return func(next jrpc.HandlerFunc) jrpc.HandlerFunc {
+			return func(ctx context.Context) (any, error) {
+				calls = append(calls, name)
+
+				return next(ctx)
+			}
+		}
+	}
+
+	router := jrpc.NewRouter()
+	router.Use(mw("first"), mw("second"))
+
+	group := router.Group("group")
+	group.Use(mw("group"))
+
+	group.Method("ping", func(ctx context.Context) (any, error) {
+		calls = append(calls, "handler")
+
+		return "pong", nil
+	})
+
+	result := router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "group.ping", "id": 1}`))
+
+	equals, err := resultsEquals(string(result), `{"jsonrpc": "2.0", "result": "pong", "id": 1}`)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(result), `{"jsonrpc": "2.0", "result": "pong", "id": 1}`)
+	}
+
+	want := []string{"first", "second", "group", "handler"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("got %v, want %v", calls, want)
+	}
+}
+
+func Test_Middlewares_UseOrder(t *testing.T) {
+	mw := func(next jrpc.HandlerFunc) jrpc.HandlerFunc {
+		return func(ctx context.Context) (any, error) {
+			return nil, &jrpc.Error{Code: -32003, Message: "unauthorized"}
+		}
+	}
+
+	ping := func(ctx context.Context) (any, error) {
+		return "pong", nil
+	}
+
+	t.Run("use after group is created", func(t *testing.T) {
+		router := jrpc.NewRouter()
+		group := router.Group("group")
+
+		router.Use(mw)
+		group.Method("ping", ping)
+
+		want := `{"jsonrpc": "2.0", "error": {"code": -32003, "message": "unauthorized"}, "id": 1}`
+		got := router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "group.ping", "id": 1}`))
+
+		equals, err := resultsEquals(string(got), want)
+		if err != nil {
+			t.Errorf("error comparing results: %s", err.Error())
+		}
+
+		if !equals {
+			t.Errorf("got %s, want %s", string(got), want)
+		}
+	})
+
+	t.Run("use after method", func(t *testing.T) {
+		router := jrpc.NewRouter()
+		router.Method("ping", ping)
+
+		defer func() {
+			if recover() == nil {
+				t.Error("Use after Method doesn't panic")
+			}
+		}()
+
+		router.Use(mw)
+	})
+
+	t.Run("use after method of group", func(t *testing.T) {
+		router := jrpc.NewRouter()
+		router.Group("group").Method("ping", ping)
+
+		defer func() {
+			if recover() == nil {
+				t.Error("Use after Method of group doesn't panic")
+			}
+		}()
+
+		router.Use(mw)
+	})
+}
+
+func Test_PanicRecovery(t *testing.T) {
+	router := jrpc.NewRouter(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	router.Method("panic", func(ctx context.Context) (any, error) {
+		panic("boom")
+	})
+	router.Method(getDataHandler.method, getDataHandler.handlerFunc)
+
+	request := []byte(`[
+		{"jsonrpc": "2.0", "method": "panic", "id": 1},
+		{"jsonrpc": "2.0", "method": "panic"},
+		{"jsonrpc": "2.0", "method": "get_data", "id": 2}
+	]`)
+	want := `[
+		{"jsonrpc": "2.0", "error": {"code": -32603, "message": "Internal error"}, "id": 1},
+		{"jsonrpc": "2.0", "result": ["hello", 5], "id": 2}
+	]`
+
+	result := router.Handle(context.Background(), request)
+
+	equals, err := resultsEquals(string(result), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(result), want)
+	}
+}
+
+func Test_Executor_Reject(t *testing.T) {
+	router := jrpc.NewRouter()
+	router.Configure(jrpc.WithMaxConcurrency(1), jrpc.WithOverloadPolicy(jrpc.OverloadReject))
+
+	started, release := make(chan struct{}), make(chan struct{})
+
+	router.Method("block", func(ctx context.Context) (any, error) {
+		close(started)
+		<-release
+
+		return nil, nil
+	})
+	router.Method(getDataHandler.method, getDataHandler.handlerFunc)
+
+	go router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "block", "id": 1}`))
+
+	<-started
+
+	want := `{"jsonrpc": "2.0", "error": {"code": -32000, "message": "Server busy"}, "id": 2}`
+	result := router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "get_data", "id": 2}`))
+
+	close(release)
+
+	equals, err := resultsEquals(string(result), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(result), want)
+	}
+}
+
+func Test_Executor_BatchParallelism(t *testing.T) {
+	router := jrpc.NewRouter()
+	router.Configure(jrpc.WithMaxConcurrency(2), jrpc.WithBatchParallelism(3))
+
+	router.Method(getDataHandler.method, getDataHandler.handlerFunc)
+
+	request, want := "[", "["
+
+	for i := 0; i < 100; i++ {
+		if i != 0 {
+			request += ","
+			want += ","
+		}
+
+		request += fmt.Sprintf(`{"jsonrpc": "2.0", "method": "get_data", "id": %d}`, i)
+		want += fmt.Sprintf(`{"jsonrpc": "2.0", "result": ["hello", 5], "id": %d}`, i)
+	}
+
+	request += "]"
+	want += "]"
+
+	result := router.Handle(context.Background(), []byte(request))
+
+	equals, err := resultsEquals(string(result), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(result), want)
+	}
+}
+
+func Test_Executor_BatchParallelism_Background(t *testing.T) {
+	router := jrpc.NewRouter()
+	router.Configure(jrpc.WithBatchParallelism(4))
+
+	var running, maxRunning atomic.Int64
+
+	router.Method("log", func(ctx context.Context) (any, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			current := maxRunning.Load()
+			if n <= current || maxRunning.CompareAndSwap(current, n) {
+				break
+			}
+		}
+
+		time.Sleep(time.Millisecond)
+
+		return nil, nil
+	}, jrpc.DontRender)
+
+	request := "["
+
+	for i := 0; i < 200; i++ {
+		if i != 0 {
+			request += ","
+		}
+
+		request += fmt.Sprintf(`{"jsonrpc": "2.0", "method": "log", "id": %d}`, i)
+	}
+
+	request += "]"
+
+	router.Handle(context.Background(), []byte(request))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := router.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := maxRunning.Load(); n > 4 {
+		t.Errorf("got %d background handlers running concurrently, want at most 4", n)
+	}
+}
+
+func Test_CallContext(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	router.Method("echo", func(ctx context.Context) (any, error) {
+		return map[string]any{
+			"id":           jrpc.RequestID(ctx),
+			"params":       string(jrpc.Params(ctx)),
+			"method":       jrpc.Method(ctx),
+			"batchIndex":   jrpc.BatchIndex(ctx),
+			"batch":        jrpc.IsBatch(ctx),
+			"notification": jrpc.IsNotification(ctx),
+		}, nil
+	})
+
+	tests := []struct {
+		name    string
+		request []byte
+		result  []byte
+	}{
+		{
+			name:    "single call",
+			request: []byte(`{"jsonrpc": "2.0", "method": "echo", "params": [1], "id": 1}`),
+			result:  []byte(`{"jsonrpc": "2.0", "result": {"id": "1", "params": "[1]", "method": "echo", "batchIndex": -1, "batch": false, "notification": false}, "id": 1}`),
+		},
+		{
+			name: "batch",
+			request: []byte(`[
+				{"jsonrpc": "2.0", "method": "echo", "params": [1], "id": 1},
+				{"jsonrpc": "2.0", "method": "echo", "params": [2], "id": 2},
+				{"jsonrpc": "2.0", "method": "echo", "params": [3], "id": 3}
+			]`),
+			result: []byte(`[
+				{"jsonrpc": "2.0", "result": {"id": "1", "params": "[1]", "method": "echo", "batchIndex": 0, "batch": true, "notification": false}, "id": 1},
+				{"jsonrpc": "2.0", "result": {"id": "2", "params": "[2]", "method": "echo", "batchIndex": 1, "batch": true, "notification": false}, "id": 2},
+				{"jsonrpc": "2.0", "result": {"id": "3", "params": "[3]", "method": "echo", "batchIndex": 2, "batch": true, "notification": false}, "id": 3}
+			]`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				result := router.Handle(context.Background(), tt.request)
+
+				equals, err := resultsEquals(string(result), string(tt.result))
+				if err != nil {
+					t.Fatalf("error comparing results: %s", err.Error())
+				}
+
+				if !equals {
+					t.Fatalf("got %s, want %s", string(result), string(tt.result))
+				}
+			}
+		})
+	}
+}
+
+func Test_BatchOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  jrpc.RouterOption
+	}{
+		{name: "ordered batch", opt: jrpc.WithOrderedBatch()},
+		{name: "sequential batch", opt: jrpc.WithSequentialBatch()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := jrpc.NewRouter()
+			router.Configure(tt.opt)
+
+			router.Method("sleep", func(ctx context.Context) (any, error) {
+				p, err := jrpc.ParamsTo[[1]int](ctx)
+				if err != nil {
+					return nil, err
+				}
+
+				time.Sleep(time.Duration(p[0]) * time.Millisecond)
+
+				return p[0], nil
+			})
+
+			result := router.Handle(context.Background(), []byte(`[
+				{"jsonrpc": "2.0", "method": "sleep", "params": [30], "id": 1},
+				{"jsonrpc": "2.0", "method": "sleep", "params": [20], "id": 2},
+				{"jsonrpc": "2.0", "method": "sleep", "params": [10], "id": 3}
+			]`))
+
+			var responses []struct {
+				ID int `json:"id"`
+			}
+
+			if err := json.Unmarshal(result, &responses); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, resp := range responses {
+				if resp.ID != i+1 {
+					t.Fatalf("got %s, want responses in request order", string(result))
+				}
+			}
+		})
+	}
+}
+
+func Test_SequentialBatch(t *testing.T) {
+	router := jrpc.NewRouter()
+	router.Configure(jrpc.WithSequentialBatch())
+
+	var counter int
+
+	router.Method("increment", func(ctx context.Context) (any, error) {
+		time.Sleep(time.Millisecond)
+
+		counter++
+
+		return counter, nil
+	})
+
+	want := `[
+		{"jsonrpc": "2.0", "result": 1, "id": 1},
+		{"jsonrpc": "2.0", "result": 2, "id": 2},
+		{"jsonrpc": "2.0", "result": 3, "id": 3}
+	]`
+	result := router.Handle(context.Background(), []byte(`[
+		{"jsonrpc": "2.0", "method": "increment", "id": 1},
+		{"jsonrpc": "2.0", "method": "increment", "id": 2},
+		{"jsonrpc": "2.0", "method": "increment", "id": 3}
+	]`))
+
+	equals, err := resultsEquals(string(result), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(result), want)
+	}
+}
+
+func Test_SequentialBatch_BackgroundMembers(t *testing.T) {
+	router := jrpc.NewRouter()
+	router.Configure(jrpc.WithSequentialBatch())
+
+	var value atomic.Int64
+
+	router.Method("set", func(ctx context.Context) (any, error) {
+		time.Sleep(20 * time.Millisecond)
+
+		value.Store(42)
+
+		return nil, nil
+	}, jrpc.DontRender)
+
+	router.Method("add", func(ctx context.Context) (any, error) {
+		time.Sleep(20 * time.Millisecond)
+
+		value.Add(1)
+
+		return nil, nil
+	})
+
+	router.Method("get", func(ctx context.Context) (any, error) {
+		return value.Load(), nil
+	})
+
+	want := `[{"jsonrpc": "2.0", "result": 43, "id": 2}]`
+	result := router.Handle(context.Background(), []byte(`[
+		{"jsonrpc": "2.0", "method": "set", "id": 1},
+		{"jsonrpc": "2.0", "method": "add"},
+		{"jsonrpc": "2.0", "method": "get", "id": 2}
+	]`))
+
+	equals, err := resultsEquals(string(result), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(result), want)
+	}
+}
+
+func Test_RenderEscaping(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	router.Method("error", func(ctx context.Context) (any, error) {
+		err := jrpc.InternalError("something \"went\" wrong\n\t\\")
+		err.Data = "<data>"
+
+		return nil, err
+	})
+
+	want := `{"jsonrpc": "2.0", "error": {"code": -32603, "message": "something \"went\" wrong\n\t\\", "data": "<data>"}, "id": 1}`
+	result := router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "error", "id": 1}`))
+
+	equals, err := resultsEquals(string(result), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(result), want)
+	}
+}
@@ -0,0 +1,348 @@
+package jrpc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/valyala/fastjson"
+)
+
+// ParamsSchema validates params of the method against JSON Schema before the handler is called,
+// after middlewares of the router, as validate tags of Register.
+// Keywords type, enum, properties, required, additionalProperties, items, minItems, maxItems,
+// minLength, maxLength, minimum and maximum are supported. Absent params are validated as empty object
+// or array, according to the schema type. The schema is also used as params schema in OpenRPC document.
+func ParamsSchema(schema Schema) Option {
+	return func(h *handler) {
+		h.paramsSchema = schema
+	}
+}
+
+// validateSchema wraps the handler with params validation against the schema.
+func validateSchema(schema Schema, handlerFunc HandlerFunc) HandlerFunc {
+	return func(ctx context.Context) (any, error) {
+		fieldErrors, err := validateParamsSchema(schema, Params(ctx))
+		if err != nil {
+			return nil, InvalidParamsError()
+		}
+
+		if len(fieldErrors) != 0 {
+			return nil, invalidParamsFieldsError(fieldErrors)
+		}
+
+		return handlerFunc(ctx)
+	}
+}
+
+// validateParamsSchema returns the field errors of params, or the error if params are not valid JSON.
+func validateParamsSchema(schema Schema, params []byte) ([]FieldError, error) {
+	if params == nil {
+		switch {
+		case schemaAllowsType(schema, "object"):
+			params = []byte("{}")
+		case schemaAllowsType(schema, "array"):
+			params = []byte("[]")
+		default:
+			return nil, nil
+		}
+	}
+
+	var parser fastjson.Parser
+
+	value, err := parser.ParseBytes(params)
+	if err != nil {
+		return nil, err
+	}
+
+	var fieldErrors []FieldError
+
+	validateSchemaValue(schema, value, "", &fieldErrors)
+
+	return fieldErrors, nil
+}
+
+func validateSchemaValue(schema Schema, value *fastjson.Value, path string, fieldErrors *[]FieldError) {
+	fail := func(rule, msg string) {
+		*fieldErrors = append(*fieldErrors, FieldError{Field: path, Rule: rule, Message: msg})
+	}
+
+	if types := schemaTypes(schema); len(types) != 0 && !slices.ContainsFunc(types, func(t string) bool { return jsonTypeIs(value, t) }) {
+		fail("type", "must be of type "+strings.Join(types, " or "))
+
+		return
+	}
+
+	if enum, ok := schema["enum"]; ok && !enumContains(enum, value) {
+		fail("enum", "must be one of: "+formatEnum(enum))
+	}
+
+	switch value.Type() {
+	case fastjson.TypeObject:
+		validateSchemaObject(schema, value, path, fieldErrors)
+
+	case fastjson.TypeArray:
+		items, _ := value.Array()
+
+		if limit, ok := schemaNumber(schema, "minItems"); ok && float64(len(items)) < limit {
+			fail("minItems", fmt.Sprintf("must contain at least %v items", limit))
+		}
+
+		if limit, ok := schemaNumber(schema, "maxItems"); ok && float64(len(items)) > limit {
+			fail("maxItems", fmt.Sprintf("must contain at most %v items", limit))
+		}
+
+		if itemSchema, ok := asSchema(schema["items"]); ok {
+			for i, item := range items {
+				validateSchemaValue(itemSchema, item, path+"["+strconv.Itoa(i)+"]", fieldErrors)
+			}
+		}
+
+	case fastjson.TypeString:
+		length := float64(utf8.RuneCount(value.GetStringBytes()))
+
+		if limit, ok := schemaNumber(schema, "minLength"); ok && length < limit {
+			fail("minLength", fmt.Sprintf("must be at least %v characters", limit))
+		}
+
+		if limit, ok := schemaNumber(schema, "maxLength"); ok && length > limit {
+			fail("maxLength", fmt.Sprintf("must be at most %v characters", limit))
+		}
+
+	case fastjson.TypeNumber:
+		num := value.GetFloat64()
+
+		if limit, ok := schemaNumber(schema, "minimum"); ok && num < limit {
+			fail("minimum", fmt.Sprintf("must be at least %v", limit))
+		}
+
+		if limit, ok := schemaNumber(schema, "maximum"); ok && num > limit {
+			fail("maximum", fmt.Sprintf("must be at most %v", limit))
+		}
+	}
+}
+
+func validateSchemaObject(schema Schema, value *fastjson.Value, path string, fieldErrors *[]FieldError) {
+	obj, _ := value.Object()
+
+	for _, name := range schemaStrings(schema["required"]) {
+		if obj.Get(name) == nil {
+			*fieldErrors = append(*fieldErrors, FieldError{Field: joinPath(path, name), Rule: "required", Message: "value is required"})
+		}
+	}
+
+	properties := schemaProperties(schema["properties"])
+	additional := schema["additionalProperties"]
+
+	obj.Visit(func(key []byte, fieldValue *fastjson.Value) {
+		name := string(key)
+
+		if propertySchema, ok := properties[name]; ok {
+			validateSchemaValue(propertySchema, fieldValue, joinPath(path, name), fieldErrors)
+
+			return
+		}
+
+		if allowed, ok := additional.(bool); ok && !allowed {
+			*fieldErrors = append(*fieldErrors, FieldError{Field: joinPath(path, name), Rule: "additionalProperties", Message: "unknown field"})
+
+			return
+		}
+
+		if additionalSchema, ok := asSchema(additional); ok {
+			validateSchemaValue(additionalSchema, fieldValue, joinPath(path, name), fieldErrors)
+		}
+	})
+}
+
+func jsonTypeIs(value *fastjson.Value, t string) bool {
+	switch t {
+	case "object":
+		return value.Type() == fastjson.TypeObject
+	case "array":
+		return value.Type() == fastjson.TypeArray
+	case "string":
+		return value.Type() == fastjson.TypeString
+	case "number":
+		return value.Type() == fastjson.TypeNumber
+	case "integer":
+		if value.Type() != fastjson.TypeNumber {
+			return false
+		}
+
+		num := value.GetFloat64()
+
+		return num == math.Trunc(num) && !math.IsInf(num, 0)
+	case "boolean":
+		return value.Type() == fastjson.TypeTrue || value.Type() == fastjson.TypeFalse
+	case "null":
+		return value.Type() == fastjson.TypeNull
+	default:
+		return true
+	}
+}
+
+func schemaAllowsType(schema Schema, t string) bool {
+	return slices.Contains(schemaTypes(schema), t)
+}
+
+func schemaTypes(schema Schema) []string {
+	if t, ok := schema["type"].(string); ok {
+		return []string{t}
+	}
+
+	return schemaStrings(schema["type"])
+}
+
+func schemaStrings(v any) []string {
+	switch v := v.(type) {
+	case []string:
+		return v
+	case []any:
+		strs := make([]string, 0, len(v))
+
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				strs = append(strs, str)
+			}
+		}
+
+		return strs
+	default:
+		return nil
+	}
+}
+
+func schemaProperties(v any) map[string]Schema {
+	properties := map[string]Schema{}
+
+	switch v := v.(type) {
+	case map[string]Schema:
+		return v
+	case map[string]any:
+		for name, property := range v {
+			if propertySchema, ok := asSchema(property); ok {
+				properties[name] = propertySchema
+			}
+		}
+	}
+
+	return properties
+}
+
+func asSchema(v any) (Schema, bool) {
+	switch v := v.(type) {
+	case Schema:
+		return v, true
+	case map[string]any:
+		return v, true
+	default:
+		return nil, false
+	}
+}
+
+func schemaNumber(schema Schema, keyword string) (float64, bool) {
+	return toFloat(schema[keyword])
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case interface{ Float64() (float64, error) }:
+		f, err := v.Float64()
+
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func enumContains(enum any, value *fastjson.Value) bool {
+	var values []any
+
+	switch enum := enum.(type) {
+	case []any:
+		values = enum
+	case []string:
+		for _, item := range enum {
+			values = append(values, item)
+		}
+	default:
+		return true
+	}
+
+	for _, item := range values {
+		if str, ok := item.(string); ok {
+			if value.Type() == fastjson.TypeString && string(value.GetStringBytes()) == str {
+				return true
+			}
+
+			continue
+		}
+
+		if num, ok := toFloat(item); ok {
+			if value.Type() == fastjson.TypeNumber && value.GetFloat64() == num {
+				return true
+			}
+
+			continue
+		}
+
+		switch item := item.(type) {
+		case bool:
+			if jsonTypeIs(value, "boolean") && value.GetBool() == item {
+				return true
+			}
+		case nil:
+			if value.Type() == fastjson.TypeNull {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func formatEnum(enum any) string {
+	switch enum := enum.(type) {
+	case []string:
+		return strings.Join(enum, ", ")
+	case []any:
+		strs := make([]string, 0, len(enum))
+
+		for _, item := range enum {
+			strs = append(strs, fmt.Sprint(item))
+		}
+
+		return strings.Join(strs, ", ")
+	default:
+		return fmt.Sprint(enum)
+	}
+}
@@ -0,0 +1,93 @@
+package jrpc
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var errRouterShutdown = errors.New("router is shut down")
+
+// lifecycle tracks DontRender handlers running in background.
+type lifecycle struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	closing bool
+	wg      sync.WaitGroup
+}
+
+func newLifecycle() *lifecycle {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &lifecycle{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// start registers the background handler and returns its context cancelled when Shutdown deadline is exceeded.
+// The context of stateless transports such as HTTP is detached from the request, as it ends with the response.
+// The context of persistent connections is kept, so the handler is still cancelled when the connection is closed.
+func (l *lifecycle) start(ctx context.Context) (context.Context, func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closing {
+		return nil, nil, errRouterShutdown
+	}
+
+	l.wg.Add(1)
+
+	if Conn(ctx) == nil {
+		ctx = context.WithoutCancel(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(l.ctx, cancel)
+
+	return ctx, func() {
+		stop()
+		cancel()
+		l.wg.Done()
+	}, nil
+}
+
+func (l *lifecycle) shutdown(ctx context.Context) error {
+	l.mu.Lock()
+	l.closing = true
+	l.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		l.cancel()
+
+		return ctx.Err()
+	}
+}
+
+func (l *lifecycle) close() {
+	l.mu.Lock()
+	l.closing = true
+	l.mu.Unlock()
+
+	l.cancel()
+}
+
+// Shutdown stops starting new background DontRender handlers and waits for running ones.
+// If ctx is done before, it cancels context of background handlers and returns ctx error.
+// Notifications are not background handlers: they run inline with the request, as calls do,
+// so they are bound by the batch parallelism and are waited for by the transport.
+func (r *Router) Shutdown(ctx context.Context) error {
+	return r.engine.lifecycle.shutdown(ctx)
+}
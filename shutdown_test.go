@@ -0,0 +1,225 @@
+package jrpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+func Test_Shutdown_WaitsForBackgroundHandlers(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	var (
+		done      atomic.Bool
+		cancelled atomic.Bool
+	)
+
+	router.Method("update", func(ctx context.Context) (any, error) {
+		time.Sleep(50 * time.Millisecond)
+
+		cancelled.Store(ctx.Err() != nil)
+		done.Store(true)
+
+		return nil, nil
+	}, jrpc.DontRender)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if res := router.Handle(ctx, []byte(`{"jsonrpc": "2.0", "method": "update", "id": 1}`)); res != nil {
+		t.Errorf("got %s, want no response", string(res))
+	}
+
+	cancel()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Second)
+	defer shutdownCancel()
+
+	if err := router.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+
+	if !done.Load() {
+		t.Error("shutdown returned before background handler finished")
+	}
+
+	if cancelled.Load() {
+		t.Error("background handler context is cancelled with request context")
+	}
+}
+
+func Test_Shutdown_Deadline(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	started, cancelled := make(chan struct{}), make(chan struct{})
+
+	router.Method("update", func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+
+		return nil, nil
+	}, jrpc.DontRender)
+
+	router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "update", "id": 1}`))
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := router.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("background handler context is not cancelled after shutdown deadline")
+	}
+}
+
+func Test_Shutdown_DropsNewBackgroundHandlers(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	var called atomic.Bool
+
+	router.Method("update", func(ctx context.Context) (any, error) {
+		called.Store(true)
+
+		return nil, nil
+	}, jrpc.DontRender)
+
+	if err := router.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "update", "id": 1}`))
+
+	time.Sleep(20 * time.Millisecond)
+
+	if called.Load() {
+		t.Error("background handler is started after shutdown")
+	}
+}
+
+func Test_HTTP_Shutdown(t *testing.T) {
+	router := jrpc.NewHTTPRouter(":8080")
+
+	var done atomic.Bool
+
+	router.Method("update", func(ctx context.Context) (any, error) {
+		time.Sleep(50 * time.Millisecond)
+		done.Store(true)
+
+		return nil, nil
+	}, jrpc.DontRender)
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"jsonrpc": "2.0", "method": "update", "id": 1}`))
+	router.Handle(httptest.NewRecorder(), r)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := router.Shutdown(ctx); err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+
+	if !done.Load() {
+		t.Error("shutdown returned before DontRender handler finished")
+	}
+}
+
+func Test_Notification_RunsInline(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	var done atomic.Bool
+
+	router.Method("update", func(ctx context.Context) (any, error) {
+		time.Sleep(20 * time.Millisecond)
+		done.Store(true)
+
+		return nil, nil
+	})
+
+	router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "update"}`))
+
+	if !done.Load() {
+		t.Error("handle returned before notification handler finished")
+	}
+}
+
+func Test_WebSocket_BackgroundHandlerCancelledOnClose(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	started, cancelled := make(chan struct{}), make(chan struct{})
+
+	router.Method("watch", func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+
+		return nil, nil
+	}, jrpc.DontRender)
+
+	srv := httptest.NewServer(jrpc.NewWebSocketHandler(router))
+	defer srv.Close()
+
+	client := dialWebSocket(t, srv.URL)
+
+	client.write(t, []byte(`{"jsonrpc": "2.0", "method": "watch", "id": 1}`))
+
+	<-started
+
+	client.conn.Close()
+
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Error("background handler context is not cancelled after connection is closed")
+	}
+}
+
+func Test_HTTP_Shutdown_AllRoutes(t *testing.T) {
+	admin := jrpc.NewRouter()
+
+	router := jrpc.NewHTTPRouter(":8080", jrpc.WithRoute("/admin", admin))
+
+	cancelled := make(chan string, 2)
+
+	for name, r := range map[string]*jrpc.Router{"main": router.Router, "admin": admin} {
+		started := make(chan struct{})
+
+		r.Method("block", func(ctx context.Context) (any, error) {
+			close(started)
+			<-ctx.Done()
+			cancelled <- name
+
+			return nil, nil
+		}, jrpc.DontRender)
+
+		r.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "block", "id": 1}`))
+
+		<-started
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := router.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("background handler of the route is not cancelled after shutdown deadline")
+		}
+	}
+}
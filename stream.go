@@ -0,0 +1,419 @@
+package jrpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/textproto"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type Framing interface {
+	ReadMessage(r *bufio.Reader) ([]byte, error)
+	WriteMessage(w io.Writer, msg []byte) error
+}
+
+// Built-in framings limit messages by WithMaxRequestSize of the router, or by 32 MiB if it is not set.
+var (
+	// ContentLengthFraming frames messages with LSP-style "Content-Length: <n>\r\n\r\n" header.
+	ContentLengthFraming Framing = contentLengthFraming{}
+	// NewlineFraming frames messages as newline-delimited JSON.
+	NewlineFraming Framing = newlineFraming{}
+	// LengthPrefixFraming frames messages with 4-byte big-endian length prefix.
+	LengthPrefixFraming Framing = lengthPrefixFraming{}
+)
+
+// defaultStreamMaxMessageSize limits messages of built-in framings if the router has no WithMaxRequestSize limit.
+const defaultStreamMaxMessageSize = 32 << 20
+
+// frameLimit returns the max message size of the framing, the default one if it is not set.
+func frameLimit(maxSize int64) int64 {
+	if maxSize <= 0 {
+		return defaultStreamMaxMessageSize
+	}
+
+	return maxSize
+}
+
+// sizeLimitedFraming is implemented by framings that can skip too large messages without reading them into memory.
+type sizeLimitedFraming interface {
+	withMaxSize(maxSize int64) Framing
+}
+
+// readFrameBody reads the message of known length, too large message is discarded without allocation.
+func readFrameBody(r *bufio.Reader, length, maxSize int64) ([]byte, error) {
+	if length > frameLimit(maxSize) {
+		if _, err := io.CopyN(io.Discard, r, length); err != nil {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, ErrMessageTooLarge
+	}
+
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+type contentLengthFraming struct {
+	maxSize int64
+}
+
+func (f contentLengthFraming) withMaxSize(maxSize int64) Framing {
+	f.maxSize = maxSize
+
+	return f
+}
+
+func (f contentLengthFraming) ReadMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+
+	tp := textproto.NewReader(r)
+
+	for first := true; ; first = false {
+		line, err := tp.ReadLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) && !first {
+				return nil, io.ErrUnexpectedEOF
+			}
+
+			return nil, err
+		}
+
+		if line == "" {
+			if first {
+				continue
+			}
+
+			break
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header line %q", line)
+		}
+
+		if textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name)) != "Content-Length" {
+			continue
+		}
+
+		length, err = strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || length < 0 {
+			return nil, fmt.Errorf("invalid Content-Length %q", value)
+		}
+	}
+
+	if length < 0 {
+		return nil, errors.New("missing Content-Length header")
+	}
+
+	return readFrameBody(r, int64(length), f.maxSize)
+}
+
+func (f contentLengthFraming) WriteMessage(w io.Writer, msg []byte) error {
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(msg)); err != nil {
+		return err
+	}
+
+	_, err := w.Write(msg)
+
+	return err
+}
+
+type newlineFraming struct {
+	maxSize int64
+}
+
+func (f newlineFraming) withMaxSize(maxSize int64) Framing {
+	f.maxSize = maxSize
+
+	return f
+}
+
+func (f newlineFraming) ReadMessage(r *bufio.Reader) ([]byte, error) {
+	for {
+		line, err := f.readLine(r)
+
+		line = bytes.TrimSpace(line)
+		if len(line) != 0 {
+			return line, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (f newlineFraming) readLine(r *bufio.Reader) ([]byte, error) {
+	var (
+		line     []byte
+		tooLarge bool
+	)
+
+	for {
+		chunk, err := r.ReadSlice('\n')
+
+		if !tooLarge {
+			line = append(line, chunk...)
+
+			if int64(len(bytes.TrimSpace(line))) > frameLimit(f.maxSize) {
+				line, tooLarge = nil, true
+			}
+		}
+
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+
+		if tooLarge {
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+
+			return nil, ErrMessageTooLarge
+		}
+
+		return line, err
+	}
+}
+
+func (f newlineFraming) WriteMessage(w io.Writer, msg []byte) error {
+	_, err := w.Write(append(msg, '\n'))
+
+	return err
+}
+
+type lengthPrefixFraming struct {
+	maxSize int64
+}
+
+func (f lengthPrefixFraming) withMaxSize(maxSize int64) Framing {
+	f.maxSize = maxSize
+
+	return f
+}
+
+func (f lengthPrefixFraming) ReadMessage(r *bufio.Reader) ([]byte, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(r, prefix[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	return readFrameBody(r, int64(binary.BigEndian.Uint32(prefix[:])), f.maxSize)
+}
+
+func (f lengthPrefixFraming) WriteMessage(w io.Writer, msg []byte) error {
+	if uint64(len(msg)) > math.MaxUint32 {
+		return errors.New("message is too big for length prefix")
+	}
+
+	frame := make([]byte, 0, len(msg)+4)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(msg)))
+	frame = append(frame, msg...)
+
+	_, err := w.Write(frame)
+
+	return err
+}
+
+type StreamOption func(*StreamTransport)
+
+func WithFraming(framing Framing) StreamOption {
+	return func(transport *StreamTransport) {
+		transport.framing = framing
+	}
+}
+
+// WithWireEncoding sets binary encoding of messages, by default messages are JSON.
+func WithWireEncoding(encoding WireEncoding) StreamOption {
+	return func(transport *StreamTransport) {
+		transport.encoding = encoding
+	}
+}
+
+// StreamTransport serves JSON-RPC over a pair of reader and writer, such as stdin and stdout.
+type StreamTransport struct {
+	router *Router
+
+	reader   *bufio.Reader
+	writer   io.Writer
+	closer   io.Closer
+	framing  Framing
+	encoding WireEncoding
+
+	writeMu sync.Mutex
+}
+
+func NewStreamTransport(router *Router, r io.Reader, w io.Writer, opts ...StreamOption) *StreamTransport {
+	transport := &StreamTransport{
+		router:  router,
+		reader:  bufio.NewReader(r),
+		writer:  w,
+		framing: ContentLengthFraming,
+	}
+
+	transport.closer, _ = r.(io.Closer)
+
+	for _, opt := range opts {
+		opt(transport)
+	}
+
+	return transport
+}
+
+// Serve reads messages until EOF and handles them concurrently. It waits for in-flight requests
+// and returns nil on EOF, or the read error otherwise. A panic during handling or writing of the message
+// closes the transport: the reader is closed if it implements io.Closer, and Serve returns the error
+// once the read is done.
+func (transport *StreamTransport) Serve(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ctx = setConnection(ctx, newConnection(ctx, transport.router.engine.codec, transport.write))
+
+	framing := transport.framing
+
+	maxSize := frameLimit(transport.router.engine.limits.maxRequestSize)
+	if limitedFraming, ok := framing.(sizeLimitedFraming); ok {
+		framing = limitedFraming.withMaxSize(maxSize)
+	}
+
+	broken := make(chan error, 1)
+
+	wg := &sync.WaitGroup{}
+	defer wg.Wait()
+
+	for {
+		msg, err := framing.ReadMessage(transport.reader)
+
+		select {
+		case panicErr := <-broken:
+			return panicErr
+		default:
+		}
+
+		if errors.Is(err, ErrMessageTooLarge) {
+			transport.writeError(requestTooLargeError(maxSize))
+
+			continue
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			// a panic of one message must close only this transport, not the whole process
+			defer func() {
+				if p := recover(); p != nil {
+					transport.router.engine.logger.Error(fmt.Sprintf("panic during handling stream message: %v", p), "stack", string(debug.Stack()))
+
+					select {
+					case broken <- fmt.Errorf("jrpc: panic during handling stream message: %v", p):
+						cancel()
+						transport.close()
+					default:
+					}
+				}
+			}()
+
+			msgCtx, scope := withResponseScope(ctx)
+			defer scope.release()
+
+			res := transport.handle(msgCtx, msg)
+			if res == nil {
+				return
+			}
+
+			if err := transport.write(res); err != nil {
+				transport.router.engine.logger.Error(fmt.Sprintf("error during stream write: %v", err.Error()))
+			}
+		}()
+	}
+}
+
+func (transport *StreamTransport) close() {
+	if transport.closer == nil {
+		return
+	}
+
+	if err := transport.closer.Close(); err != nil {
+		transport.router.engine.logger.Error(fmt.Sprintf("error during stream close: %v", err.Error()))
+	}
+}
+
+func (transport *StreamTransport) handle(ctx context.Context, msg []byte) []byte {
+	if transport.encoding == nil {
+		return transport.router.engine.handle(ctx, msg)
+	}
+
+	// the size limit applies to the wire bytes, custom framings don't check it
+	if limit := transport.router.engine.limits.maxRequestSize; limit > 0 && int64(len(msg)) > limit {
+		buf := getBuffer()
+		defer putBuffer(buf)
+
+		transport.router.engine.renderError(buf, requestTooLargeError(limit))
+
+		return bytes.Clone(buf.Bytes())
+	}
+
+	msg, err := transport.encoding.ToJSON(msg)
+	if err != nil {
+		return errorParsingJSONString
+	}
+
+	return transport.router.engine.handleTranslated(ctx, msg)
+}
+
+func (transport *StreamTransport) writeError(jrpcErr *Error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	transport.router.engine.renderError(buf, jrpcErr)
+
+	if err := transport.write(buf.Bytes()); err != nil {
+		transport.router.engine.logger.Error(fmt.Sprintf("error during stream write: %v", err.Error()))
+	}
+}
+
+func (transport *StreamTransport) write(msg []byte) error {
+	if transport.encoding != nil {
+		var err error
+		if msg, err = transport.encoding.FromJSON(msg); err != nil {
+			return err
+		}
+	}
+
+	transport.writeMu.Lock()
+	defer transport.writeMu.Unlock()
+
+	return transport.framing.WriteMessage(transport.writer, msg)
+}
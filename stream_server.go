@@ -0,0 +1,186 @@
+package jrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+var ErrServerClosed = errors.New("jrpc: server closed")
+
+// StreamServer serves JSON-RPC over raw stream connections accepted from net.Listener, such as TCP or unix sockets.
+type StreamServer struct {
+	listener net.Listener
+	router   *Router
+	opts     []StreamOption
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	conns    map[net.Conn]struct{}
+	closing  bool
+	connsWg  sync.WaitGroup
+	shutdown chan struct{}
+}
+
+func NewStreamServer(listener net.Listener, router *Router, opts ...StreamOption) *StreamServer {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &StreamServer{
+		listener: listener,
+		router:   router,
+		opts:     opts,
+		ctx:      ctx,
+		cancel:   cancel,
+		conns:    make(map[net.Conn]struct{}),
+		shutdown: make(chan struct{}),
+	}
+}
+
+// Serve accepts connections until Shutdown or Close is called, then it returns ErrServerClosed.
+func (srv *StreamServer) Serve() error {
+	for {
+		conn, err := srv.listener.Accept()
+		if err != nil {
+			select {
+			case <-srv.shutdown:
+				return ErrServerClosed
+			default:
+			}
+
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				time.Sleep(5 * time.Millisecond)
+
+				continue
+			}
+
+			return err
+		}
+
+		if !srv.trackConn(conn) {
+			conn.Close()
+
+			return ErrServerClosed
+		}
+
+		go srv.serveConn(conn)
+	}
+}
+
+func (srv *StreamServer) trackConn(conn net.Conn) bool {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+
+	if srv.closing {
+		return false
+	}
+
+	srv.conns[conn] = struct{}{}
+	srv.connsWg.Add(1)
+
+	return true
+}
+
+func (srv *StreamServer) serveConn(conn net.Conn) {
+	defer func() {
+		srv.mu.Lock()
+		delete(srv.conns, conn)
+		srv.mu.Unlock()
+
+		conn.Close()
+		srv.connsWg.Done()
+	}()
+
+	// a panic in framing must close only this connection, not the whole process
+	defer func() {
+		if p := recover(); p != nil {
+			srv.router.engine.logger.Error(fmt.Sprintf("panic during serving stream connection: %v", p), "stack", string(debug.Stack()))
+		}
+	}()
+
+	err := NewStreamTransport(srv.router, conn, conn, srv.opts...).Serve(srv.ctx)
+	if err == nil {
+		return
+	}
+
+	srv.mu.Lock()
+	closing := srv.closing
+	srv.mu.Unlock()
+
+	if !closing {
+		srv.router.engine.logger.Error(fmt.Sprintf("error during serving stream connection: %v", err.Error()))
+	}
+}
+
+// Shutdown stops accepting connections and reading new requests, then waits for in-flight requests.
+// If ctx is done before, it cancels handlers context, closes connections and returns ctx error.
+func (srv *StreamServer) Shutdown(ctx context.Context) error {
+	srv.mu.Lock()
+
+	if !srv.closing {
+		srv.closing = true
+		close(srv.shutdown)
+	}
+
+	err := srv.listener.Close()
+
+	for conn := range srv.conns {
+		_ = conn.SetReadDeadline(time.Now())
+	}
+
+	srv.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		srv.connsWg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		srv.cancel()
+
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+
+		return err
+	case <-ctx.Done():
+		srv.Close()
+
+		return ctx.Err()
+	}
+}
+
+// Close immediately closes the listener and all connections.
+func (srv *StreamServer) Close() error {
+	srv.mu.Lock()
+
+	if !srv.closing {
+		srv.closing = true
+		close(srv.shutdown)
+	}
+
+	err := srv.listener.Close()
+
+	for conn := range srv.conns {
+		_ = conn.Close()
+	}
+
+	srv.mu.Unlock()
+
+	srv.cancel()
+
+	if errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,250 @@
+package jrpc_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+func Test_StreamServer_GracefulShutdown(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	started := make(chan struct{})
+
+	router.Method("slow", func(ctx context.Context) (any, error) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+
+		return "done", nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := jrpc.NewStreamServer(listener, router, jrpc.WithFraming(jrpc.LengthPrefixFraming))
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- srv.Serve() }()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err = jrpc.LengthPrefixFraming.WriteMessage(conn, []byte(`{"jsonrpc": "2.0", "method": "slow", "id": 1}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err = srv.Shutdown(ctx); err != nil {
+		t.Fatalf("got %v, want nil", err)
+	}
+
+	if err = <-serveErr; !errors.Is(err, jrpc.ErrServerClosed) {
+		t.Errorf("got %v, want %v", err, jrpc.ErrServerClosed)
+	}
+
+	msg, err := jrpc.LengthPrefixFraming.ReadMessage(bufio.NewReader(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"jsonrpc": "2.0", "result": "done", "id": 1}`
+
+	equals, err := resultsEquals(string(msg), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(msg), want)
+	}
+}
+
+// panicFraming panics on the message "panic", other messages are framed by newline.
+type panicFraming struct{}
+
+func (panicFraming) ReadMessage(r *bufio.Reader) ([]byte, error) {
+	msg, err := jrpc.NewlineFraming.ReadMessage(r)
+	if err == nil && string(msg) == "panic" {
+		panic("broken framing")
+	}
+
+	return msg, err
+}
+
+func (panicFraming) WriteMessage(w io.Writer, msg []byte) error {
+	return jrpc.NewlineFraming.WriteMessage(w, msg)
+}
+
+func Test_StreamServer_ConnectionPanic(t *testing.T) {
+	router := jrpc.NewRouter(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	router.Method(subtractHandler.method, subtractHandler.handlerFunc)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := jrpc.NewStreamServer(listener, router, jrpc.WithFraming(panicFraming{}))
+	defer srv.Close()
+
+	go srv.Serve()
+
+	broken, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broken.Close()
+
+	if _, err = broken.Write([]byte("panic\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = bufio.NewReader(broken).ReadByte(); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v, want connection closed", err)
+	}
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err = conn.Write([]byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := jrpc.NewlineFraming.ReadMessage(bufio.NewReader(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"jsonrpc": "2.0", "result": 19, "id": 1}`
+
+	equals, err := resultsEquals(string(msg), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(msg), want)
+	}
+}
+
+// writePanicFraming panics on writing the message that contains "boom", messages are framed by newline.
+type writePanicFraming struct{}
+
+func (writePanicFraming) ReadMessage(r *bufio.Reader) ([]byte, error) {
+	return jrpc.NewlineFraming.ReadMessage(r)
+}
+
+func (writePanicFraming) WriteMessage(w io.Writer, msg []byte) error {
+	if bytes.Contains(msg, []byte("boom")) {
+		panic("write boom")
+	}
+
+	return jrpc.NewlineFraming.WriteMessage(w, msg)
+}
+
+func Test_StreamServer_WritePanic(t *testing.T) {
+	router := jrpc.NewRouter(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	router.Method(subtractHandler.method, subtractHandler.handlerFunc)
+	router.Method("boom", func(ctx context.Context) (any, error) {
+		return "boom", nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := jrpc.NewStreamServer(listener, router, jrpc.WithFraming(writePanicFraming{}))
+	defer srv.Close()
+
+	go srv.Serve()
+
+	broken, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer broken.Close()
+
+	if _, err = broken.Write([]byte(`{"jsonrpc": "2.0", "method": "boom", "id": 1}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = bufio.NewReader(broken).ReadByte(); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v, want connection closed", err)
+	}
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err = conn.Write([]byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := jrpc.NewlineFraming.ReadMessage(bufio.NewReader(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"jsonrpc": "2.0", "result": 19, "id": 1}`
+
+	equals, err := resultsEquals(string(msg), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(msg), want)
+	}
+}
+
+func Test_StreamTransport_WritePanic(t *testing.T) {
+	router := jrpc.NewRouter(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	router.Method("boom", func(ctx context.Context) (any, error) {
+		return "boom", nil
+	})
+
+	r, w := io.Pipe()
+	defer w.Close()
+
+	transport := jrpc.NewStreamTransport(router, r, io.Discard, jrpc.WithFraming(writePanicFraming{}))
+
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- transport.Serve(context.Background()) }()
+
+	if _, err := w.Write([]byte(`{"jsonrpc": "2.0", "method": "boom", "id": 1}` + "\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-serveErr:
+		if err == nil || !strings.Contains(err.Error(), "write boom") {
+			t.Errorf("got %v, want panic error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("transport is not closed after panic")
+	}
+}
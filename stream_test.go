@@ -0,0 +1,67 @@
+package jrpc_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+func Test_StreamTransport(t *testing.T) {
+	tests := []struct {
+		name    string
+		framing jrpc.Framing
+		input   string
+		output  string
+	}{
+		{
+			name:    "content-length framing",
+			framing: jrpc.ContentLengthFraming,
+			input: "Content-Length: 69\r\n\r\n" +
+				`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}` +
+				"Content-Length: 61\r\nContent-Type: application/vscode-jsonrpc; charset=utf-8\r\n\r\n" +
+				`{"jsonrpc": "2.0", "method": "update", "params": [1,2,3,4,5]}`,
+			output: `{"jsonrpc": "2.0", "result": 19, "id": 1}`,
+		},
+		{
+			name:    "newline framing",
+			framing: jrpc.NewlineFraming,
+			input: `{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}` + "\n\n" +
+				`{"jsonrpc": "2.0", "method": "update", "params": [1,2,3,4,5]}`,
+			output: `{"jsonrpc": "2.0", "result": 19, "id": 1}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := jrpc.NewRouter()
+
+			router.Method(subtractHandler.method, subtractHandler.handlerFunc)
+			router.Method(notificationHandler.method, notificationHandler.handlerFunc)
+
+			out := &bytes.Buffer{}
+
+			err := jrpc.NewStreamTransport(router, strings.NewReader(tt.input), out, jrpc.WithFraming(tt.framing)).
+				Serve(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msg, err := tt.framing.ReadMessage(bufio.NewReader(out))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			equals, err := resultsEquals(string(msg), tt.output)
+			if err != nil {
+				t.Errorf("error comparing results: %s", err.Error())
+			}
+
+			if !equals {
+				t.Errorf("got %s, want %s", string(msg), tt.output)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package jrpc
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Timeout limits the time of handling the method, on timeout the call fails with Request timeout error.
+// It overrides the router default set by WithTimeout.
+func Timeout(d time.Duration) Option {
+	return func(h *handler) {
+		h.timeout = d
+	}
+}
+
+// WithTimeout sets the default timeout of methods without Timeout option.
+func WithTimeout(d time.Duration) RouterOption {
+	return func(router *engine) {
+		router.timeout = d
+	}
+}
+
+// WithBatchTimeout limits the time of handling the whole batch. Members not finished by the deadline
+// fail with Request timeout error, other members return their results.
+func WithBatchTimeout(d time.Duration) RouterOption {
+	return func(router *engine) {
+		router.batchTimeout = d
+	}
+}
+
+func (router *engine) handlerTimeout(h *handler) time.Duration {
+	if h.timeout > 0 {
+		return h.timeout
+	}
+
+	return router.timeout
+}
+
+// contextError converts exceeded deadline into Request timeout error.
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return RequestTimeoutError()
+	}
+
+	return err
+}
+
+type callResult struct {
+	res any
+	err error
+}
+
+// callWithDeadline returns Request timeout error as soon as the deadline of ctx is exceeded,
+// the handler keeps running in background until it returns, then release is called.
+func (router *engine) callWithDeadline(ctx context.Context, h *handler, release func()) (any, error) {
+	done := make(chan callResult, 1)
+
+	go func() {
+		defer release()
+
+		res, err := router.call(ctx, h)
+		done <- callResult{res: res, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil && errors.Is(r.err, context.DeadlineExceeded) {
+			return nil, RequestTimeoutError()
+		}
+
+		return r.res, r.err
+	case <-ctx.Done():
+		return nil, contextError(ctx.Err())
+	}
+}
@@ -0,0 +1,148 @@
+package jrpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+func slowHandler(ctx context.Context) (any, error) {
+	select {
+	case <-time.After(time.Second):
+		return "slow", nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func blockingHandler(ctx context.Context) (any, error) {
+	time.Sleep(200 * time.Millisecond)
+
+	return "blocking", nil
+}
+
+func fastHandler(ctx context.Context) (any, error) {
+	return "fast", nil
+}
+
+func Test_Timeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []jrpc.RouterOption
+		methods func(router *jrpc.Router)
+		request string
+		result  string
+	}{
+		{
+			name: "method timeout",
+			methods: func(router *jrpc.Router) {
+				router.Method("slow", slowHandler, jrpc.Timeout(10*time.Millisecond))
+			},
+			request: `{"jsonrpc": "2.0", "method": "slow", "id": 1}`,
+			result:  `{"jsonrpc": "2.0", "error": {"code": -32001, "message": "Request timeout"}, "id": 1}`,
+		},
+		{
+			name: "handler ignoring context",
+			methods: func(router *jrpc.Router) {
+				router.Method("blocking", blockingHandler, jrpc.Timeout(10*time.Millisecond))
+			},
+			request: `{"jsonrpc": "2.0", "method": "blocking", "id": 1}`,
+			result:  `{"jsonrpc": "2.0", "error": {"code": -32001, "message": "Request timeout"}, "id": 1}`,
+		},
+		{
+			name: "router default timeout",
+			opts: []jrpc.RouterOption{jrpc.WithTimeout(10 * time.Millisecond)},
+			methods: func(router *jrpc.Router) {
+				router.Method("slow", slowHandler)
+			},
+			request: `{"jsonrpc": "2.0", "method": "slow", "id": 1}`,
+			result:  `{"jsonrpc": "2.0", "error": {"code": -32001, "message": "Request timeout"}, "id": 1}`,
+		},
+		{
+			name: "method timeout overrides router default",
+			opts: []jrpc.RouterOption{jrpc.WithTimeout(10 * time.Millisecond)},
+			methods: func(router *jrpc.Router) {
+				router.Method("blocking", blockingHandler, jrpc.Timeout(time.Second))
+			},
+			request: `{"jsonrpc": "2.0", "method": "blocking", "id": 1}`,
+			result:  `{"jsonrpc": "2.0", "result": "blocking", "id": 1}`,
+		},
+		{
+			name: "batch member timeout",
+			methods: func(router *jrpc.Router) {
+				router.Method("slow", slowHandler, jrpc.Timeout(10*time.Millisecond))
+				router.Method("fast", fastHandler)
+			},
+			request: `[
+				{"jsonrpc": "2.0", "method": "slow", "id": 1},
+				{"jsonrpc": "2.0", "method": "fast", "id": 2}
+			]`,
+			result: `[
+				{"jsonrpc": "2.0", "error": {"code": -32001, "message": "Request timeout"}, "id": 1},
+				{"jsonrpc": "2.0", "result": "fast", "id": 2}
+			]`,
+		},
+		{
+			name: "batch timeout",
+			opts: []jrpc.RouterOption{jrpc.WithBatchTimeout(20 * time.Millisecond)},
+			methods: func(router *jrpc.Router) {
+				router.Method("slow", slowHandler)
+				router.Method("fast", fastHandler)
+			},
+			request: `[
+				{"jsonrpc": "2.0", "method": "slow", "id": 1},
+				{"jsonrpc": "2.0", "method": "fast", "id": 2},
+				{"jsonrpc": "2.0", "method": "slow", "id": 3}
+			]`,
+			result: `[
+				{"jsonrpc": "2.0", "error": {"code": -32001, "message": "Request timeout"}, "id": 1},
+				{"jsonrpc": "2.0", "result": "fast", "id": 2},
+				{"jsonrpc": "2.0", "error": {"code": -32001, "message": "Request timeout"}, "id": 3}
+			]`,
+		},
+		{
+			name: "sequential batch timeout",
+			opts: []jrpc.RouterOption{jrpc.WithBatchTimeout(20 * time.Millisecond), jrpc.WithSequentialBatch()},
+			methods: func(router *jrpc.Router) {
+				router.Method("slow", slowHandler)
+				router.Method("fast", fastHandler)
+			},
+			request: `[
+				{"jsonrpc": "2.0", "method": "fast", "id": 1},
+				{"jsonrpc": "2.0", "method": "slow", "id": 2},
+				{"jsonrpc": "2.0", "method": "fast", "id": 3}
+			]`,
+			result: `[
+				{"jsonrpc": "2.0", "result": "fast", "id": 1},
+				{"jsonrpc": "2.0", "error": {"code": -32001, "message": "Request timeout"}, "id": 2},
+				{"jsonrpc": "2.0", "error": {"code": -32001, "message": "Request timeout"}, "id": 3}
+			]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := jrpc.NewRouter()
+			router.Configure(tt.opts...)
+
+			tt.methods(router)
+
+			start := time.Now()
+			result := router.Handle(context.Background(), []byte(tt.request))
+
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("handling took %s", elapsed)
+			}
+
+			equals, err := resultsEquals(string(result), tt.result)
+			if err != nil {
+				t.Errorf("error comparing results: %s", err.Error())
+			}
+
+			if !equals {
+				t.Errorf("got %s, want %s", string(result), tt.result)
+			}
+		})
+	}
+}
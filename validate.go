@@ -0,0 +1,280 @@
+package jrpc
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError describes the params field that failed validation. Invalid params errors of failed validation
+// contain the list of field errors in Data.
+type FieldError struct {
+	// Field is the path of the field by JSON names, e.g. "user.tags[1]", empty for params itself.
+	Field string `json:"field"`
+	// Rule is the failed rule of validate tag or the keyword of JSON Schema, e.g. "required" or "minimum".
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func invalidParamsFieldsError(fieldErrors []FieldError) *Error {
+	invalidParamsErr := InvalidParamsError()
+	invalidParamsErr.Data = fieldErrors
+
+	return invalidParamsErr
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+type validationRule struct {
+	name  string
+	param string
+	num   float64
+	enum  []string
+}
+
+type fieldValidator struct {
+	index  int
+	name   string
+	inline bool
+	rules  []validationRule
+	nested *structValidator
+}
+
+// structValidator checks values of struct against rules of validate tags:
+// required, min=<n>, max=<n> and oneof=<space separated values>.
+// Rules min and max limit numbers, length of strings in characters and length of slices and maps.
+// Nil pointers, slices, maps and interfaces are treated as absent, other rules are not checked for them,
+// so optional fields with rules should be pointers. Rules are checked for zero values of other types.
+type structValidator struct {
+	fields []fieldValidator
+}
+
+// newStructValidator parses validate tags of the type and nested types, it returns nil if there are no tags.
+// It panics on malformed tags, as they are programming errors found at registration.
+func newStructValidator(t reflect.Type) *structValidator {
+	return buildStructValidator(t, map[reflect.Type]bool{})
+}
+
+func buildStructValidator(t reflect.Type, seen map[reflect.Type]bool) *structValidator {
+	t = elemStructType(t)
+	if t == nil || seen[t] {
+		return nil
+	}
+
+	seen[t] = true
+	defer delete(seen, t)
+
+	v := &structValidator{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		fv := fieldValidator{
+			index:  i,
+			name:   name,
+			inline: field.Anonymous && name == "",
+			rules:  parseValidateTag(t, field),
+			nested: buildStructValidator(field.Type, seen),
+		}
+
+		if fv.name == "" {
+			fv.name = field.Name
+		}
+
+		if len(fv.rules) != 0 || fv.nested != nil {
+			v.fields = append(v.fields, fv)
+		}
+	}
+
+	if len(v.fields) == 0 {
+		return nil
+	}
+
+	return v
+}
+
+// elemStructType returns the struct type of the value, element of slice, array or map, or nil if it isn't struct.
+func elemStructType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+		case reflect.Struct:
+			return t
+		default:
+			return nil
+		}
+	}
+}
+
+func parseValidateTag(t reflect.Type, field reflect.StructField) []validationRule {
+	tag := field.Tag.Get("validate")
+	if tag == "" {
+		return nil
+	}
+
+	var rules []validationRule
+
+	for _, part := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		rule := validationRule{name: name, param: param}
+
+		switch name {
+		case "required":
+		case "min", "max":
+			num, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				panic(fmt.Sprintf("jrpc: invalid validate rule %q of field %s.%s", part, t.Name(), field.Name))
+			}
+
+			rule.num = num
+		case "oneof":
+			rule.enum = strings.Fields(param)
+		default:
+			panic(fmt.Sprintf("jrpc: unknown validate rule %q of field %s.%s", name, t.Name(), field.Name))
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+func (v *structValidator) validate(value reflect.Value) []FieldError {
+	var fieldErrors []FieldError
+
+	v.validateValue(value, "", &fieldErrors)
+
+	return fieldErrors
+}
+
+func (v *structValidator) validateValue(value reflect.Value, path string, fieldErrors *[]FieldError) {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !value.IsNil() {
+			v.validateValue(value.Elem(), path, fieldErrors)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			v.validateValue(value.Index(i), path+"["+strconv.Itoa(i)+"]", fieldErrors)
+		}
+
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			v.validateValue(value.MapIndex(key), joinPath(path, fmt.Sprint(key)), fieldErrors)
+		}
+
+	case reflect.Struct:
+		for _, field := range v.fields {
+			fieldPath := path
+			if !field.inline {
+				fieldPath = joinPath(path, field.name)
+			}
+
+			v.validateField(field, value.Field(field.index), fieldPath, fieldErrors)
+		}
+	}
+}
+
+func (v *structValidator) validateField(field fieldValidator, value reflect.Value, path string, fieldErrors *[]FieldError) {
+	if value.IsZero() && slices.ContainsFunc(field.rules, func(rule validationRule) bool { return rule.name == "required" }) {
+		*fieldErrors = append(*fieldErrors, FieldError{Field: path, Rule: "required", Message: "value is required"})
+
+		return
+	}
+
+	if isAbsent(value) {
+		return
+	}
+
+	for _, rule := range field.rules {
+		if rule.name == "required" {
+			continue
+		}
+
+		if msg := checkRule(rule, value); msg != "" {
+			*fieldErrors = append(*fieldErrors, FieldError{Field: path, Rule: rule.name, Message: msg})
+		}
+	}
+
+	if field.nested != nil {
+		field.nested.validateValue(value, path, fieldErrors)
+	}
+}
+
+func isAbsent(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
+
+func checkRule(rule validationRule, value reflect.Value) string {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ""
+		}
+
+		value = value.Elem()
+	}
+
+	switch rule.name {
+	case "min", "max":
+		size, unit, ok := ruleSize(value)
+		if !ok {
+			return ""
+		}
+
+		if rule.name == "min" && size < rule.num {
+			return fmt.Sprintf("must be at least %s%s", rule.param, unit)
+		}
+
+		if rule.name == "max" && size > rule.num {
+			return fmt.Sprintf("must be at most %s%s", rule.param, unit)
+		}
+
+	case "oneof":
+		if !slices.Contains(rule.enum, fmt.Sprint(value)) {
+			return "must be one of: " + strings.Join(rule.enum, ", ")
+		}
+	}
+
+	return ""
+}
+
+// ruleSize returns the value checked by min and max rules: number itself or length.
+func ruleSize(value reflect.Value) (float64, string, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), "", true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), " characters", true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), " items", true
+	default:
+		return 0, "", false
+	}
+}
@@ -0,0 +1,242 @@
+package jrpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type createUserParams struct {
+	Name      string    `json:"name" validate:"required,max=8"`
+	Age       int       `json:"age" validate:"min=18,max=130"`
+	Role      *string   `json:"role,omitempty" validate:"oneof=admin user"`
+	Tags      []string  `json:"tags" validate:"max=2"`
+	Address   *address  `json:"address,omitempty"`
+	Addresses []address `json:"addresses,omitempty"`
+}
+
+func Test_Register_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		request string
+		result  string
+	}{
+		{
+			name:    "valid params",
+			request: `{"jsonrpc": "2.0", "method": "users.create", "params": {"name": "bob", "age": 30, "role": "admin"}, "id": 1}`,
+			result:  `{"jsonrpc": "2.0", "result": "bob", "id": 1}`,
+		},
+		{
+			name:    "missing required and out of range",
+			request: `{"jsonrpc": "2.0", "method": "users.create", "params": {"age": 12, "tags": ["a", "b", "c"]}, "id": 1}`,
+			result: `{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params", "data": [
+				{"field": "name", "rule": "required", "message": "value is required"},
+				{"field": "age", "rule": "min", "message": "must be at least 18"},
+				{"field": "tags", "rule": "max", "message": "must be at most 2 items"}
+			]}, "id": 1}`,
+		},
+		{
+			name:    "string length and enum",
+			request: `{"jsonrpc": "2.0", "method": "users.create", "params": {"name": "alexander", "age": 30, "role": "root"}, "id": 1}`,
+			result: `{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params", "data": [
+				{"field": "name", "rule": "max", "message": "must be at most 8 characters"},
+				{"field": "role", "rule": "oneof", "message": "must be one of: admin, user"}
+			]}, "id": 1}`,
+		},
+		{
+			name:    "nested structs",
+			request: `{"jsonrpc": "2.0", "method": "users.create", "params": {"name": "bob", "age": 30, "address": {}, "addresses": [{"city": "Kyiv"}, {}]}, "id": 1}`,
+			result: `{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params", "data": [
+				{"field": "address.city", "rule": "required", "message": "value is required"},
+				{"field": "addresses[1].city", "rule": "required", "message": "value is required"}
+			]}, "id": 1}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := jrpc.NewRouter()
+
+			jrpc.Register(router, "users.create", func(ctx context.Context, p createUserParams) (string, error) {
+				return p.Name, nil
+			})
+
+			result := router.Handle(context.Background(), []byte(tt.request))
+
+			equals, err := resultsEquals(string(result), tt.result)
+			if err != nil {
+				t.Errorf("error comparing results: %s", err.Error())
+			}
+
+			if !equals {
+				t.Errorf("got %s, want %s", string(result), tt.result)
+			}
+		})
+	}
+}
+
+type zeroValueParams struct {
+	Count int    `json:"count" validate:"min=1"`
+	Kind  string `json:"kind" validate:"oneof=a b"`
+	Limit *int   `json:"limit,omitempty" validate:"min=1"`
+}
+
+func Test_Register_Validation_ZeroValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		request string
+		result  string
+	}{
+		{
+			name:    "valid params",
+			request: `{"jsonrpc": "2.0", "method": "count", "params": {"count": 1, "kind": "a"}, "id": 1}`,
+			result:  `{"jsonrpc": "2.0", "result": 1, "id": 1}`,
+		},
+		{
+			name:    "zero values",
+			request: `{"jsonrpc": "2.0", "method": "count", "params": {"count": 0, "kind": "", "limit": 0}, "id": 1}`,
+			result: `{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params", "data": [
+				{"field": "count", "rule": "min", "message": "must be at least 1"},
+				{"field": "kind", "rule": "oneof", "message": "must be one of: a, b"},
+				{"field": "limit", "rule": "min", "message": "must be at least 1"}
+			]}, "id": 1}`,
+		},
+		{
+			name:    "absent values",
+			request: `{"jsonrpc": "2.0", "method": "count", "params": {}, "id": 1}`,
+			result: `{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params", "data": [
+				{"field": "count", "rule": "min", "message": "must be at least 1"},
+				{"field": "kind", "rule": "oneof", "message": "must be one of: a, b"}
+			]}, "id": 1}`,
+		},
+		{
+			name:    "negative value",
+			request: `{"jsonrpc": "2.0", "method": "count", "params": {"count": -3, "kind": "b"}, "id": 1}`,
+			result: `{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params", "data": [
+				{"field": "count", "rule": "min", "message": "must be at least 1"}
+			]}, "id": 1}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := jrpc.NewRouter()
+
+			jrpc.Register(router, "count", func(ctx context.Context, p zeroValueParams) (int, error) {
+				return p.Count, nil
+			})
+
+			result := router.Handle(context.Background(), []byte(tt.request))
+
+			equals, err := resultsEquals(string(result), tt.result)
+			if err != nil {
+				t.Errorf("error comparing results: %s", err.Error())
+			}
+
+			if !equals {
+				t.Errorf("got %s, want %s", string(result), tt.result)
+			}
+		})
+	}
+}
+
+func Test_ParamsSchema(t *testing.T) {
+	schema := jrpc.Schema{
+		"type":     "object",
+		"required": []string{"name", "age"},
+		"properties": map[string]any{
+			"name": jrpc.Schema{"type": "string", "minLength": 2},
+			"age":  jrpc.Schema{"type": "integer", "minimum": 18},
+			"role": jrpc.Schema{"enum": []any{"admin", "user"}},
+			"tags": jrpc.Schema{"type": "array", "maxItems": 2, "items": jrpc.Schema{"type": "string"}},
+		},
+		"additionalProperties": false,
+	}
+
+	tests := []struct {
+		name    string
+		request string
+		result  string
+	}{
+		{
+			name:    "valid params",
+			request: `{"jsonrpc": "2.0", "method": "users.create", "params": {"name": "bob", "age": 30, "role": "user", "tags": ["a"]}, "id": 1}`,
+			result:  `{"jsonrpc": "2.0", "result": "ok", "id": 1}`,
+		},
+		{
+			name:    "absent params",
+			request: `{"jsonrpc": "2.0", "method": "users.create", "id": 1}`,
+			result: `{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params", "data": [
+				{"field": "name", "rule": "required", "message": "value is required"},
+				{"field": "age", "rule": "required", "message": "value is required"}
+			]}, "id": 1}`,
+		},
+		{
+			name:    "invalid params",
+			request: `{"jsonrpc": "2.0", "method": "users.create", "params": {"name": "b", "age": 17.5, "role": "root", "tags": ["a", 1], "extra": true}, "id": 1}`,
+			result: `{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params", "data": [
+				{"field": "name", "rule": "minLength", "message": "must be at least 2 characters"},
+				{"field": "age", "rule": "type", "message": "must be of type integer"},
+				{"field": "role", "rule": "enum", "message": "must be one of: admin, user"},
+				{"field": "tags[1]", "rule": "type", "message": "must be of type string"},
+				{"field": "extra", "rule": "additionalProperties", "message": "unknown field"}
+			]}, "id": 1}`,
+		},
+		{
+			name:    "wrong params type",
+			request: `{"jsonrpc": "2.0", "method": "users.create", "params": [1, 2], "id": 1}`,
+			result: `{"jsonrpc": "2.0", "error": {"code": -32602, "message": "Invalid params", "data": [
+				{"field": "", "rule": "type", "message": "must be of type object"}
+			]}, "id": 1}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := jrpc.NewRouter()
+
+			router.Method("users.create", func(ctx context.Context) (any, error) {
+				return "ok", nil
+			}, jrpc.ParamsSchema(schema))
+
+			result := router.Handle(context.Background(), []byte(tt.request))
+
+			equals, err := resultsEquals(string(result), tt.result)
+			if err != nil {
+				t.Errorf("error comparing results: %s", err.Error())
+			}
+
+			if !equals {
+				t.Errorf("got %s, want %s", string(result), tt.result)
+			}
+		})
+	}
+}
+
+func Test_ParamsSchema_AfterMiddlewares(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	router.Use(func(next jrpc.HandlerFunc) jrpc.HandlerFunc {
+		return func(ctx context.Context) (any, error) {
+			return nil, &jrpc.Error{Code: -32003, Message: "unauthorized"}
+		}
+	})
+
+	router.Method("users.create", func(ctx context.Context) (any, error) {
+		return "ok", nil
+	}, jrpc.ParamsSchema(jrpc.Schema{"type": "object", "required": []string{"name"}}))
+
+	want := `{"jsonrpc": "2.0", "error": {"code": -32003, "message": "unauthorized"}, "id": 1}`
+	got := router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "users.create", "params": {}, "id": 1}`))
+
+	equals, err := resultsEquals(string(got), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(got), want)
+	}
+}
@@ -0,0 +1,56 @@
+package jrpc
+
+import "github.com/valyala/fastjson"
+
+type ValidationMode int
+
+const (
+	// ValidationLenient accepts requests without "jsonrpc" member, scalar params and non-scalar ids.
+	ValidationLenient ValidationMode = iota
+	// ValidationStrict rejects requests that don't follow JSON-RPC 2.0 specification with Invalid Request error.
+	ValidationStrict
+)
+
+func WithValidation(mode ValidationMode) RouterOption {
+	return func(router *engine) {
+		router.validation = mode
+	}
+}
+
+func validateRequest(v *fastjson.Value) string {
+	if v.Type() != fastjson.TypeObject {
+		return "request must be an object"
+	}
+
+	if version := v.Get("jsonrpc"); version == nil || version.Type() != fastjson.TypeString || string(version.GetStringBytes()) != "2.0" {
+		return `"jsonrpc" member must be exactly "2.0"`
+	}
+
+	if method := v.Get("method"); method == nil || method.Type() != fastjson.TypeString {
+		return `"method" member must be a string`
+	}
+
+	if params := v.Get("params"); params != nil && params.Type() != fastjson.TypeObject && params.Type() != fastjson.TypeArray {
+		return `"params" member must be an object or an array`
+	}
+
+	if !validRequestID(v) {
+		return `"id" member must be a string, a number or null`
+	}
+
+	return ""
+}
+
+func validRequestID(v *fastjson.Value) bool {
+	id := v.Get("id")
+	if id == nil {
+		return true
+	}
+
+	switch id.Type() {
+	case fastjson.TypeString, fastjson.TypeNumber, fastjson.TypeNull:
+		return true
+	default:
+		return false
+	}
+}
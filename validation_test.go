@@ -0,0 +1,71 @@
+package jrpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+func Test_StrictValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		request []byte
+		result  []byte
+	}{
+		{
+			name:    "valid request",
+			request: []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 1}`),
+			result:  []byte(`{"jsonrpc": "2.0", "result": 19, "id": 1}`),
+		},
+		{
+			name:    "missing jsonrpc",
+			request: []byte(`{"method": "subtract", "params": [42, 23], "id": 1}`),
+			result:  []byte(`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request", "data": "\"jsonrpc\" member must be exactly \"2.0\""}, "id": 1}`),
+		},
+		{
+			name:    "wrong jsonrpc version",
+			request: []byte(`{"jsonrpc": "1.0", "method": "subtract", "params": [42, 23], "id": 1}`),
+			result:  []byte(`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request", "data": "\"jsonrpc\" member must be exactly \"2.0\""}, "id": 1}`),
+		},
+		{
+			name:    "non-string method",
+			request: []byte(`{"jsonrpc": "2.0", "method": 1, "params": [42, 23], "id": 1}`),
+			result:  []byte(`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request", "data": "\"method\" member must be a string"}, "id": 1}`),
+		},
+		{
+			name:    "scalar params",
+			request: []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": "bar", "id": 1}`),
+			result:  []byte(`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request", "data": "\"params\" member must be an object or an array"}, "id": 1}`),
+		},
+		{
+			name:    "object id",
+			request: []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": {"a": 1}}`),
+			result:  []byte(`{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request", "data": "\"id\" member must be a string, a number or null"}, "id": null}`),
+		},
+		{
+			name:    "non-object request in batch",
+			request: []byte(`[1]`),
+			result:  []byte(`[{"jsonrpc": "2.0", "error": {"code": -32600, "message": "Invalid Request", "data": "request must be an object"}, "id": null}]`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := jrpc.NewRouter()
+			router.Configure(jrpc.WithValidation(jrpc.ValidationStrict))
+
+			router.Method(subtractHandler.method, subtractHandler.handlerFunc)
+
+			result := router.Handle(context.Background(), tt.request)
+
+			equals, err := resultsEquals(string(result), string(tt.result))
+			if err != nil {
+				t.Errorf("error comparing results: %s", err.Error())
+			}
+
+			if !equals {
+				t.Errorf("got %s, want %s", string(result), string(tt.result))
+			}
+		})
+	}
+}
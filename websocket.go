@@ -0,0 +1,422 @@
+package jrpc
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+
+	wsCloseNormal          = 1000
+	wsCloseProtocolError   = 1002
+	wsCloseUnsupportedData = 1003
+	wsCloseMessageTooBig   = 1009
+
+	defaultWSMaxMessageSize = 32 << 20
+)
+
+var errWSClosed = errors.New("websocket: connection closed")
+
+type WebSocketOption func(*WebSocketHandler)
+
+type WebSocketHandler struct {
+	router *Router
+
+	maxMessageSize int64
+	checkOrigin    func(r *http.Request) bool
+}
+
+func NewWebSocketHandler(router *Router, opts ...WebSocketOption) *WebSocketHandler {
+	wsHandler := &WebSocketHandler{
+		router:         router,
+		maxMessageSize: defaultWSMaxMessageSize,
+	}
+
+	for _, opt := range opts {
+		opt(wsHandler)
+	}
+
+	return wsHandler
+}
+
+func WithMaxMessageSize(size int64) WebSocketOption {
+	return func(wsHandler *WebSocketHandler) {
+		wsHandler.maxMessageSize = size
+	}
+}
+
+func WithCheckOrigin(checkOrigin func(r *http.Request) bool) WebSocketOption {
+	return func(wsHandler *WebSocketHandler) {
+		wsHandler.checkOrigin = checkOrigin
+	}
+}
+
+func (wsHandler *WebSocketHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if wsHandler.checkOrigin != nil && !wsHandler.checkOrigin(r) {
+		http.Error(w, "403 forbidden", http.StatusForbidden)
+
+		return
+	}
+
+	conn, err := wsUpgrade(w, r)
+	if err != nil {
+		wsHandler.router.engine.logger.Error(fmt.Sprintf("error during websocket upgrade: %v", err.Error()))
+
+		return
+	}
+
+	conn.maxMessageSize = wsHandler.maxMessageSize
+	if limit := wsHandler.router.engine.limits.maxRequestSize; limit > 0 && limit < conn.maxMessageSize {
+		conn.maxMessageSize = limit
+	}
+
+	wsHandler.serve(r.Context(), conn)
+}
+
+func (wsHandler *WebSocketHandler) serve(ctx context.Context, conn *wsConn) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	ctx = setConnection(ctx, newConnection(ctx, wsHandler.router.engine.codec, func(msg []byte) error {
+		return conn.writeMessage(wsOpText, msg)
+	}))
+	wg := &sync.WaitGroup{}
+
+	defer func() {
+		cancel()
+		wg.Wait()
+		conn.close()
+	}()
+
+	for {
+		msg, err := conn.readMessage()
+		if err != nil {
+			if !errors.Is(err, errWSClosed) && !errors.Is(err, io.EOF) {
+				wsHandler.router.engine.logger.Error(fmt.Sprintf("error during websocket read: %v", err.Error()))
+			}
+
+			return
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			msgCtx, scope := withResponseScope(ctx)
+			defer scope.release()
+
+			res := wsHandler.router.engine.handle(msgCtx, msg)
+			if res == nil {
+				return
+			}
+
+			if err := conn.writeMessage(wsOpText, res); err != nil && !errors.Is(err, errWSClosed) {
+				wsHandler.router.engine.logger.Error(fmt.Sprintf("error during websocket write: %v", err.Error()))
+			}
+		}()
+	}
+}
+
+type wsConn struct {
+	netConn net.Conn
+	reader  *bufio.Reader
+
+	maxMessageSize int64
+
+	writeMu sync.Mutex
+	closed  bool
+}
+
+func wsUpgrade(w http.ResponseWriter, r *http.Request) (*wsConn, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "405 method not allowed", http.StatusMethodNotAllowed)
+
+		return nil, errors.New("websocket: method is not GET")
+	}
+
+	if !headerContainsToken(r.Header, "Connection", "upgrade") || !headerContainsToken(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "400 bad request", http.StatusBadRequest)
+
+		return nil, errors.New("websocket: not a websocket handshake")
+	}
+
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "426 upgrade required", http.StatusUpgradeRequired)
+
+		return nil, errors.New("websocket: unsupported version")
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "400 bad request", http.StatusBadRequest)
+
+		return nil, errors.New("websocket: missing Sec-WebSocket-Key")
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "500 internal server error", http.StatusInternalServerError)
+
+		return nil, errors.New("websocket: response writer doesn't support hijacking")
+	}
+
+	netConn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = netConn.SetDeadline(time.Time{}); err != nil {
+		netConn.Close()
+
+		return nil, err
+	}
+
+	accept := sha1.Sum([]byte(key + wsGUID))
+
+	handshake := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(accept[:]) + "\r\n\r\n"
+
+	if _, err = netConn.Write([]byte(handshake)); err != nil {
+		netConn.Close()
+
+		return nil, err
+	}
+
+	return &wsConn{
+		netConn:        netConn,
+		reader:         rw.Reader,
+		maxMessageSize: defaultWSMaxMessageSize,
+	}, nil
+}
+
+func headerContainsToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, v := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (conn *wsConn) readMessage() ([]byte, error) {
+	var (
+		msg     []byte
+		msgType byte
+	)
+
+	for {
+		fin, opcode, payload, err := conn.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case wsOpPing:
+			if err = conn.writeMessage(wsOpPong, payload); err != nil {
+				return nil, err
+			}
+
+			continue
+
+		case wsOpPong:
+			continue
+
+		case wsOpClose:
+			code := wsCloseNormal
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+
+			_ = conn.writeClose(code)
+
+			return nil, errWSClosed
+
+		case wsOpText, wsOpBinary:
+			if msgType != 0 {
+				_ = conn.writeClose(wsCloseProtocolError)
+
+				return nil, errors.New("websocket: unexpected data frame in fragmented message")
+			}
+
+			msgType = opcode
+
+		case wsOpContinuation:
+			if msgType == 0 {
+				_ = conn.writeClose(wsCloseProtocolError)
+
+				return nil, errors.New("websocket: unexpected continuation frame")
+			}
+
+		default:
+			_ = conn.writeClose(wsCloseProtocolError)
+
+			return nil, fmt.Errorf("websocket: unknown opcode %d", opcode)
+		}
+
+		if int64(len(msg)+len(payload)) > conn.maxMessageSize {
+			_ = conn.writeClose(wsCloseMessageTooBig)
+
+			return nil, errors.New("websocket: message too big")
+		}
+
+		msg = append(msg, payload...)
+
+		if !fin {
+			continue
+		}
+
+		if msgType != wsOpText {
+			_ = conn.writeClose(wsCloseUnsupportedData)
+
+			return nil, errors.New("websocket: binary messages are not supported")
+		}
+
+		return msg, nil
+	}
+}
+
+func (conn *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(conn.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+
+	if header[0]&0x70 != 0 {
+		_ = conn.writeClose(wsCloseProtocolError)
+
+		return false, 0, nil, errors.New("websocket: reserved bits are set")
+	}
+
+	if header[1]&0x80 == 0 {
+		_ = conn.writeClose(wsCloseProtocolError)
+
+		return false, 0, nil, errors.New("websocket: client frame is not masked")
+	}
+
+	length := int64(header[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(conn.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(conn.reader, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+
+	if opcode >= wsOpClose && (length > 125 || !fin) {
+		_ = conn.writeClose(wsCloseProtocolError)
+
+		return false, 0, nil, errors.New("websocket: invalid control frame")
+	}
+
+	if length < 0 || length > conn.maxMessageSize {
+		_ = conn.writeClose(wsCloseMessageTooBig)
+
+		return false, 0, nil, errors.New("websocket: frame too big")
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(conn.reader, mask[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(conn.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+func (conn *wsConn) writeMessage(opcode byte, payload []byte) error {
+	conn.writeMu.Lock()
+	defer conn.writeMu.Unlock()
+
+	if conn.closed {
+		return errWSClosed
+	}
+
+	return conn.writeFrame(opcode, payload)
+}
+
+func (conn *wsConn) writeFrame(opcode byte, payload []byte) error {
+	frame := make([]byte, 0, len(payload)+10)
+	frame = append(frame, 0x80|opcode)
+
+	switch length := len(payload); {
+	case length <= 125:
+		frame = append(frame, byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+
+	frame = append(frame, payload...)
+
+	_, err := conn.netConn.Write(frame)
+
+	return err
+}
+
+func (conn *wsConn) writeClose(code int) error {
+	conn.writeMu.Lock()
+	defer conn.writeMu.Unlock()
+
+	if conn.closed {
+		return errWSClosed
+	}
+
+	conn.closed = true
+
+	return conn.writeFrame(wsOpClose, binary.BigEndian.AppendUint16(nil, uint16(code)))
+}
+
+func (conn *wsConn) close() {
+	_ = conn.writeClose(wsCloseNormal)
+	_ = conn.netConn.Close()
+}
@@ -0,0 +1,327 @@
+package jrpc_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+type wsTestClient struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialWebSocket(t *testing.T, url string) *wsTestClient {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+
+	_, err = io.WriteString(conn, "GET / HTTP/1.1\r\n"+
+		"Host: localhost\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader := bufio.NewReader(conn)
+
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("got %d, want 101", resp.StatusCode)
+	}
+
+	if accept := resp.Header.Get("Sec-WebSocket-Accept"); accept != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("got %s, want s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", accept)
+	}
+
+	return &wsTestClient{conn: conn, reader: reader}
+}
+
+func (c *wsTestClient) write(t *testing.T, payload []byte) {
+	t.Helper()
+
+	mask := [4]byte{1, 2, 3, 4}
+
+	frame := []byte{0x81}
+
+	switch {
+	case len(payload) <= 125:
+		frame = append(frame, 0x80|byte(len(payload)))
+	default:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	}
+
+	frame = append(frame, mask[:]...)
+
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+
+	if _, err := c.conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *wsTestClient) read(t *testing.T) []byte {
+	t.Helper()
+
+	if err := c.conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		t.Fatal(err)
+	}
+
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		var ext [2]byte
+		if _, err := io.ReadFull(c.reader, ext[:]); err != nil {
+			t.Fatal(err)
+		}
+
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		t.Fatal(err)
+	}
+
+	return payload
+}
+
+func Test_WebSocket(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	release := make(chan struct{})
+
+	router.Method("slow", func(ctx context.Context) (any, error) {
+		<-release
+
+		return "slow", nil
+	})
+	router.Method(subtractHandler.method, subtractHandler.handlerFunc)
+
+	srv := httptest.NewServer(jrpc.NewWebSocketHandler(router))
+	defer srv.Close()
+
+	client := dialWebSocket(t, srv.URL)
+
+	client.write(t, []byte(`{"jsonrpc": "2.0", "method": "slow", "id": 1}`))
+	client.write(t, []byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": 2}`))
+
+	results := []string{
+		`{"jsonrpc": "2.0", "result": 19, "id": 2}`,
+		`{"jsonrpc": "2.0", "result": "slow", "id": 1}`,
+	}
+
+	for i, want := range results {
+		got := client.read(t)
+
+		equals, err := resultsEquals(string(got), want)
+		if err != nil {
+			t.Errorf("error comparing results: %s", err.Error())
+		}
+
+		if !equals {
+			t.Errorf("got %s, want %s", string(got), want)
+		}
+
+		if i == 0 {
+			close(release)
+		}
+	}
+}
+
+func Test_WebSocket_Subscription(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	events := make(chan int)
+	unsubscribed := make(chan struct{})
+
+	router.Method("subscribe", func(ctx context.Context) (any, error) {
+		sub, err := jrpc.NewSubscription(ctx, "subscription")
+		if err != nil {
+			return nil, err
+		}
+
+		go func() {
+			defer close(unsubscribed)
+
+			for {
+				select {
+				case <-sub.Done():
+					return
+				case event := <-events:
+					_ = sub.Notify(event)
+				}
+			}
+		}()
+
+		return sub.ID, nil
+	})
+
+	router.Method("unsubscribe", func(ctx context.Context) (any, error) {
+		p, err := jrpc.ParamsTo[[1]string](ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return jrpc.Conn(ctx).Unsubscribe(p[0]), nil
+	})
+
+	srv := httptest.NewServer(jrpc.NewWebSocketHandler(router))
+	defer srv.Close()
+
+	client := dialWebSocket(t, srv.URL)
+
+	client.write(t, []byte(`{"jsonrpc": "2.0", "method": "subscribe", "id": 1}`))
+
+	var subResp struct {
+		Result string `json:"result"`
+	}
+
+	if err := json.Unmarshal(client.read(t), &subResp); err != nil {
+		t.Fatal(err)
+	}
+
+	events <- 42
+
+	want := `{"jsonrpc": "2.0", "method": "subscription", "params": {"subscription": "` + subResp.Result + `", "result": 42}}`
+	got := client.read(t)
+
+	equals, err := resultsEquals(string(got), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(got), want)
+	}
+
+	client.write(t, []byte(`{"jsonrpc": "2.0", "method": "unsubscribe", "params": ["`+subResp.Result+`"], "id": 2}`))
+
+	want = `{"jsonrpc": "2.0", "result": true, "id": 2}`
+	got = client.read(t)
+
+	equals, err = resultsEquals(string(got), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(got), want)
+	}
+
+	select {
+	case <-unsubscribed:
+	case <-time.After(5 * time.Second):
+		t.Error("subscription is not closed after unsubscribe")
+	}
+}
+
+func Test_WebSocket_Subscription_EventsAfterResponse(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	router.Method("subscribe", func(ctx context.Context) (any, error) {
+		sub, err := jrpc.NewSubscription(ctx, "subscription")
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 1; i <= 2; i++ {
+			if err = sub.Notify(i); err != nil {
+				return nil, err
+			}
+		}
+
+		return sub.ID, nil
+	})
+
+	srv := httptest.NewServer(jrpc.NewWebSocketHandler(router))
+	defer srv.Close()
+
+	client := dialWebSocket(t, srv.URL)
+
+	client.write(t, []byte(`{"jsonrpc": "2.0", "method": "subscribe", "id": 1}`))
+
+	var subResp struct {
+		Result string `json:"result"`
+		ID     int    `json:"id"`
+	}
+
+	if err := json.Unmarshal(client.read(t), &subResp); err != nil {
+		t.Fatal(err)
+	}
+
+	if subResp.ID != 1 || subResp.Result == "" {
+		t.Fatalf("got response %+v, want subscription id before events", subResp)
+	}
+
+	for i := 1; i <= 2; i++ {
+		want := fmt.Sprintf(`{"jsonrpc": "2.0", "method": "subscription", "params": {"subscription": "%s", "result": %d}}`, subResp.Result, i)
+		got := client.read(t)
+
+		equals, err := resultsEquals(string(got), want)
+		if err != nil {
+			t.Errorf("error comparing results: %s", err.Error())
+		}
+
+		if !equals {
+			t.Errorf("got %s, want %s", string(got), want)
+		}
+	}
+}
+
+func Test_Conn_WithoutPersistentConnection(t *testing.T) {
+	router := jrpc.NewRouter()
+
+	router.Method("subscribe", func(ctx context.Context) (any, error) {
+		if jrpc.Conn(ctx) != nil {
+			t.Error("got connection, want nil")
+		}
+
+		_, err := jrpc.NewSubscription(ctx, "subscription")
+
+		return nil, err
+	})
+
+	want := `{"jsonrpc": "2.0", "error": {"code": -32603, "message": "jrpc: no persistent connection in context"}, "id": 1}`
+	got := router.Handle(context.Background(), []byte(`{"jsonrpc": "2.0", "method": "subscribe", "id": 1}`))
+
+	equals, err := resultsEquals(string(got), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(got), want)
+	}
+}
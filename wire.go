@@ -0,0 +1,81 @@
+package jrpc
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fastjson"
+)
+
+const maxWireDepth = 1000
+
+var errWireDepth = errors.New("maximum nesting depth exceeded")
+
+// WireEncoding is a binary encoding of JSON-RPC messages with the same envelope semantics as JSON.
+// Messages are translated to JSON before handling and back after rendering, so handlers work unchanged.
+type WireEncoding interface {
+	ContentType() string
+	ToJSON(msg []byte) ([]byte, error)
+	FromJSON(msg []byte) ([]byte, error)
+}
+
+var (
+	MessagePack WireEncoding = msgpackEncoding{}
+	CBOR        WireEncoding = cborEncoding{}
+)
+
+func mediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+
+	return mediaType
+}
+
+// jsonNumber classifies JSON number literal as unsigned, signed or float value.
+func jsonNumber(raw string) (u uint64, i int64, f float64, kind byte, err error) {
+	if !strings.ContainsAny(raw, ".eE") {
+		if u, err = strconv.ParseUint(raw, 10, 64); err == nil {
+			return u, 0, 0, 'u', nil
+		}
+
+		if i, err = strconv.ParseInt(raw, 10, 64); err == nil {
+			return 0, i, 0, 'i', nil
+		}
+	}
+
+	f, err = strconv.ParseFloat(raw, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, 0, 0, 0, err
+	}
+
+	return 0, 0, f, 'f', nil
+}
+
+func writeJSONFloat(buf *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return errors.New("NaN and Inf can't be represented in JSON")
+	}
+
+	start := buf.Len()
+
+	buf.Write(strconv.AppendFloat(buf.AvailableBuffer(), f, 'g', -1, 64))
+
+	// keep the value float after translating back from JSON
+	if !bytes.ContainsAny(buf.Bytes()[start:], ".eE") {
+		buf.WriteString(".0")
+	}
+
+	return nil
+}
+
+func parseJSONForWire(msg []byte) (*fastjson.Value, error) {
+	var parser fastjson.Parser
+
+	return parser.ParseBytes(msg)
+}
@@ -0,0 +1,237 @@
+package jrpc_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ananaslegend/jrpc"
+)
+
+func Test_WireEncodings(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding jrpc.WireEncoding
+		json     string
+		encoded  []byte
+	}{
+		{
+			name:     "msgpack map",
+			encoding: jrpc.MessagePack,
+			json:     `{"a":1}`,
+			encoded:  []byte{0x81, 0xa1, 'a', 0x01},
+		},
+		{
+			name:     "msgpack scalars",
+			encoding: jrpc.MessagePack,
+			json:     `[-1,-200,300,1.5,"x",true,false,null]`,
+			encoded: []byte{
+				0x98, 0xff, 0xd1, 0xff, 0x38, 0xcd, 0x01, 0x2c,
+				0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0,
+				0xa1, 'x', 0xc3, 0xc2, 0xc0,
+			},
+		},
+		{
+			name:     "cbor map",
+			encoding: jrpc.CBOR,
+			json:     `{"a":1}`,
+			encoded:  []byte{0xa1, 0x61, 'a', 0x01},
+		},
+		{
+			name:     "cbor scalars",
+			encoding: jrpc.CBOR,
+			json:     `[-1,-200,300,1.5,"x",true,false,null]`,
+			encoded: []byte{
+				0x88, 0x20, 0x38, 0xc7, 0x19, 0x01, 0x2c,
+				0xfb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0,
+				0x61, 'x', 0xf5, 0xf4, 0xf6,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.encoding.FromJSON([]byte(tt.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(encoded, tt.encoded) {
+				t.Errorf("got %x, want %x", encoded, tt.encoded)
+			}
+
+			decoded, err := tt.encoding.ToJSON(tt.encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(decoded) != tt.json {
+				t.Errorf("got %s, want %s", string(decoded), tt.json)
+			}
+		})
+	}
+}
+
+func Test_HTTP_WireEncoding(t *testing.T) {
+	for _, encoding := range []jrpc.WireEncoding{jrpc.MessagePack, jrpc.CBOR} {
+		t.Run(encoding.ContentType(), func(t *testing.T) {
+			router := jrpc.NewHTTPRouter(":8080", jrpc.WithWireEncodings(jrpc.MessagePack, jrpc.CBOR))
+
+			router.Method(namedSubtractHandler.method, namedSubtractHandler.handlerFunc)
+
+			request, err := encoding.FromJSON([]byte(`{"jsonrpc": "2.0", "method": "subtract", "params": {"subtrahend": 23, "minuend": 42}, "id": 3}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := httptest.NewRequest("POST", "/", bytes.NewReader(request))
+			r.Header.Set("Content-Type", encoding.ContentType())
+
+			w := httptest.NewRecorder()
+
+			router.Handle(w, r)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			if contentType := resp.Header.Get("Content-Type"); contentType != encoding.ContentType() {
+				t.Errorf("got %s, want %s", contentType, encoding.ContentType())
+			}
+
+			bts, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := encoding.ToJSON(bts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := `{"jsonrpc": "2.0", "result": 19, "id": 3}`
+
+			equals, err := resultsEquals(string(result), want)
+			if err != nil {
+				t.Errorf("error comparing results: %s", err.Error())
+			}
+
+			if !equals {
+				t.Errorf("got %s, want %s", string(result), want)
+			}
+		})
+	}
+}
+
+func Test_HTTP_WireEncoding_MaxRequestSize(t *testing.T) {
+	for _, encoding := range []jrpc.WireEncoding{jrpc.MessagePack, jrpc.CBOR} {
+		t.Run(encoding.ContentType(), func(t *testing.T) {
+			request, err := encoding.FromJSON([]byte(`{"jsonrpc": "2.0", "method": "subtract", "params": {"subtrahend": 23, "minuend": 42}, "id": 3}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			router := jrpc.NewRouter()
+			router.Configure(jrpc.WithMaxRequestSize(int64(len(request))))
+			router.Method(namedSubtractHandler.method, namedSubtractHandler.handlerFunc)
+
+			handler := jrpc.NewHTTPHandler(router, jrpc.WithHandlerWireEncodings(encoding))
+
+			tests := []struct {
+				name    string
+				request []byte
+				status  int
+			}{
+				{
+					name:    "wire bytes within limit",
+					request: request,
+					status:  http.StatusOK,
+				},
+				{
+					name:    "wire bytes over limit",
+					request: append(bytes.Clone(request), 0),
+					status:  http.StatusRequestEntityTooLarge,
+				},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					r := httptest.NewRequest("POST", "/", bytes.NewReader(tt.request))
+					r.Header.Set("Content-Type", encoding.ContentType())
+
+					w := httptest.NewRecorder()
+
+					handler.ServeHTTP(w, r)
+
+					if w.Code != tt.status {
+						t.Fatalf("got %d, want %d", w.Code, tt.status)
+					}
+
+					if tt.status != http.StatusOK {
+						return
+					}
+
+					result, err := encoding.ToJSON(w.Body.Bytes())
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					want := `{"jsonrpc": "2.0", "result": 19, "id": 3}`
+
+					equals, err := resultsEquals(string(result), want)
+					if err != nil {
+						t.Errorf("error comparing results: %s", err.Error())
+					}
+
+					if !equals {
+						t.Errorf("got %s, want %s", string(result), want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func Test_Stream_WireEncoding(t *testing.T) {
+	router := jrpc.NewRouter()
+	router.Method(subtractHandler.method, subtractHandler.handlerFunc)
+
+	request, err := jrpc.CBOR.FromJSON([]byte(`{"jsonrpc": "2.0", "method": "subtract", "params": [42, 23], "id": "1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in, out := &bytes.Buffer{}, &bytes.Buffer{}
+
+	if err = jrpc.LengthPrefixFraming.WriteMessage(in, request); err != nil {
+		t.Fatal(err)
+	}
+
+	err = jrpc.NewStreamTransport(router, in, out, jrpc.WithFraming(jrpc.LengthPrefixFraming), jrpc.WithWireEncoding(jrpc.CBOR)).
+		Serve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := jrpc.LengthPrefixFraming.ReadMessage(bufio.NewReader(out))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := jrpc.CBOR.ToJSON(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"jsonrpc": "2.0", "result": 19, "id": "1"}`
+
+	equals, err := resultsEquals(string(result), want)
+	if err != nil {
+		t.Errorf("error comparing results: %s", err.Error())
+	}
+
+	if !equals {
+		t.Errorf("got %s, want %s", string(result), want)
+	}
+}